@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"performance-dashboard-backend/internal/clickup"
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+)
+
+// runBackfill implements `backfill -team Art -from 2025-09-01 -to 2025-10-01 [-dry-run]`.
+func runBackfill(args []string) {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	team := fs.String("team", "", "team name from the sync config, e.g. Art")
+	fromStr := fs.String("from", "", "start date, YYYY-MM-DD or RFC3339")
+	toStr := fs.String("to", "", "end date (exclusive), YYYY-MM-DD or RFC3339")
+	dryRun := fs.Bool("dry-run", false, "print what would change instead of writing")
+	fs.Parse(args)
+
+	from, errFrom := parseCLIDate(*fromStr)
+	to, errTo := parseCLIDate(*toStr)
+	if *team == "" || errFrom != nil || errTo != nil {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	report, err := clickup.Backfill(context.Background(), *team, from, to, *dryRun, collectionmodels.SYNC_TRIGGER_CLI, func(week *clickup.BackfillWeek) {
+		status := "ok"
+		if week.Error != "" {
+			status = "error: " + week.Error
+		}
+		if week.Diff != nil {
+			fmt.Printf("%s → %s  fetched=%d new=%d changed=%d missing=%d  %s\n", week.From.Format("2006-01-02"), week.To.Format("2006-01-02"), week.Fetched, len(week.Diff.New), len(week.Diff.Changed), len(week.Diff.Missing), status)
+			return
+		}
+		fmt.Printf("%s → %s  fetched=%d inserted=%d existing=%d  %s\n", week.From.Format("2006-01-02"), week.To.Format("2006-01-02"), week.Fetched, week.Inserted, week.Existing, status)
+	})
+	if err != nil {
+		log.Fatal("Backfill error:", err)
+	}
+	fmt.Printf("Backfill %s done: %d weeks, %d inserted, %d already stored, %d failed\n", report.Team, len(report.Weeks), report.Inserted, report.Existing, report.Failed)
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// runSync implements `sync -team Art [-from ... -to ...] [-dry-run]`; without a
+// range the team's current weekly window is used.
+func runSync(args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	teamName := fs.String("team", "", "team name from the sync config, e.g. Art")
+	fromStr := fs.String("from", "", "window start, YYYY-MM-DD or RFC3339 (default: current window)")
+	toStr := fs.String("to", "", "window end, YYYY-MM-DD or RFC3339 (default: current window)")
+	dryRun := fs.Bool("dry-run", false, "print the diff against stored tasks instead of writing")
+	fs.Parse(args)
+
+	cfg := syncconfig.Get()
+	team := cfg.Team(*teamName)
+	if team == nil {
+		fmt.Fprintf(os.Stderr, "unknown team %q, expected one of %v\n", *teamName, cfg.TeamNames())
+		os.Exit(2)
+	}
+	from, to := cfg.Window(team, time.Now())
+	if *fromStr != "" || *toStr != "" {
+		var errFrom, errTo error
+		from, errFrom = parseCLIDate(*fromStr)
+		to, errTo = parseCLIDate(*toStr)
+		if errFrom != nil || errTo != nil {
+			fs.Usage()
+			os.Exit(2)
+		}
+	}
+
+	ctx := context.Background()
+	if *dryRun {
+		diff, err := clickup.DryRunTeam(ctx, team, from, to)
+		if err != nil {
+			log.Fatal("Sync error:", err)
+		}
+		out, _ := json.MarshalIndent(diff, "", "  ")
+		fmt.Println(string(out))
+		fmt.Printf("%s %s → %s: %d new, %d changed, %d missing, %d unchanged\n", team.Team, from.Format("2006-01-02"), to.Format("2006-01-02"), len(diff.New), len(diff.Changed), len(diff.Missing), diff.Unchanged)
+		return
+	}
+
+	run, err := clickup.SyncTeam(ctx, team, from, to, collectionmodels.SYNC_TRIGGER_CLI)
+	if err != nil {
+		log.Fatal("Sync error:", err)
+	}
+	fmt.Printf("%s %s → %s: fetched=%d accepted=%d inserted=%d skipped=%d rejected=%d\n", team.Team, from.Format("2006-01-02"), to.Format("2006-01-02"), run.Fetched, run.Accepted, run.Inserted, run.Skipped, run.Rejected)
+	for _, rejection := range run.Rejections {
+		fmt.Printf("  rejected %s (%s): %s\n", rejection.TaskID, rejection.TaskName, rejection.Reason)
+	}
+}
+
+// runRelabelWeeks implements `relabel-weeks [-dry-run]`: it moves records stored
+// under the Wed → Tue work week's Monday 00:00 UTC to their sync window's
+// DoneDate (Monday 09:00 local, one week later), see syncconfig.Config.DoneWeek.
+func runRelabelWeeks(args []string) {
+	fs := flag.NewFlagSet("relabel-weeks", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "count the records that would move instead of writing")
+	fs.Parse(args)
+
+	moved, err := collectionmodels.RelabelWorkWeekDoneDates(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), syncconfig.Get().Location(), *dryRun)
+	if err != nil {
+		log.Fatal("Relabel error:", err)
+	}
+	if *dryRun {
+		fmt.Printf("%d records would move to their sync window's week\n", moved)
+		return
+	}
+	fmt.Printf("%d records moved to their sync window's week\n", moved)
+}
+
+func parseCLIDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, syncconfig.Get().Location())
+}
@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"os"
 	api "performance-dashboard-backend/internal/api"
+	_ "performance-dashboard-backend/internal/asana" // registers the asana task source
 	db "performance-dashboard-backend/internal/database"
+	"performance-dashboard-backend/internal/tasksource"
 
 	"github.com/joho/godotenv"
 )
@@ -24,11 +26,40 @@ func ConnectDatabase() {
 	}
 }
 
+// InitIdentities makes the task sources resolve assignees through the members'
+// identities.
+func InitIdentities() {
+	tasksource.SetAssigneeResolver(db.ResolveAssignee)
+}
+
+func InitSessions() {
+	err := api.InitSessions(nil)
+	if err != nil {
+		log.Fatal("Session store error:", err)
+	}
+}
+
 func main() {
 	LoadEnv()
 	ConnectDatabase()
+	InitIdentities()
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backfill":
+			runBackfill(os.Args[2:])
+			return
+		case "sync":
+			runSync(os.Args[2:])
+			return
+		case "relabel-weeks":
+			runRelabelWeeks(os.Args[2:])
+			return
+		}
+	}
+
+	InitSessions()
 
-	// asana.SyncronizeWeeklyTasks()
 	api.Init()
 	log.Fatal(http.ListenAndServe(":"+os.Getenv("SERVER_PORT"), nil))
 }
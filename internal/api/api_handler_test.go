@@ -0,0 +1,132 @@
+package apihandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/session"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func managerOf(teams ...string) *Principal {
+	p := &Principal{Email: "m@example.com"}
+	for _, team := range teams {
+		p.TeamRoles = append(p.TeamRoles, &db.TeamRole{Team: team, Role: "manager"})
+	}
+	return p
+}
+
+func TestCanUpdateProjectIssue(t *testing.T) {
+	admin := &Principal{Email: "admin@example.com", TeamRoles: []*db.TeamRole{{Role: "admin"}}}
+	art := &collectionmodels.ProjectIssue{Team: "Art"}
+	unassigned := &collectionmodels.ProjectIssue{}
+
+	tests := []struct {
+		name      string
+		principal *Principal
+		stored    *collectionmodels.ProjectIssue
+		team      string
+		want      bool
+	}{
+		{"manager of the issue's team", managerOf("Art"), art, "Art", true},
+		{"manager of the issue's team, team left out", managerOf("Art"), art, "", true},
+		{"manager of another team claiming the issue", managerOf("Video"), art, "Video", false},
+		{"manager of another team naming the issue's team", managerOf("Video"), art, "Art", false},
+		{"moving to a team the caller does not manage", managerOf("Art"), art, "Video", false},
+		{"moving between two managed teams", managerOf("Art", "Video"), art, "Video", true},
+		{"claiming an issue without a team", managerOf("Art"), unassigned, "Art", false},
+		{"admin moving the issue", admin, art, "Video", true},
+	}
+	for _, tt := range tests {
+		if got := canUpdateProjectIssue(tt.principal, tt.stored, tt.team); got != tt.want {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateProjectIssueDeniesOtherTeam(t *testing.T) {
+	id := primitive.NewObjectID()
+	prev := loadProjectIssue
+	t.Cleanup(func() { loadProjectIssue = prev })
+	loadProjectIssue = func(got primitive.ObjectID) (*collectionmodels.ProjectIssue, error) {
+		if got != id {
+			return nil, nil
+		}
+		return &collectionmodels.ProjectIssue{ID: id, Project: "P", Team: "Art"}, nil
+	}
+
+	update := func(p *Principal, issueID primitive.ObjectID, team string) int {
+		body := `{"ID": "` + issueID.Hex() + `", "Team": "` + team + `", "Project": "P", "TaskType": "x", "CompletedCount": 1, "Difference": 0, "OrderCount": 1}`
+		req := httptest.NewRequest(http.MethodPost, "/post/update-project-issue", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		HandleUpdateProjectIssue(rec, req.WithContext(context.WithValue(req.Context(), principalKey{}, p)))
+		return rec.Code
+	}
+
+	if code := update(managerOf("Video"), id, "Video"); code != http.StatusForbidden {
+		t.Errorf("manager of another team: %d, want 403", code)
+	}
+	if code := update(managerOf("Art"), id, "Video"); code != http.StatusForbidden {
+		t.Errorf("move to an unmanaged team: %d, want 403", code)
+	}
+	if code := update(managerOf("Art"), primitive.NewObjectID(), "Art"); code != http.StatusNotFound {
+		t.Errorf("unknown issue: %d, want 404", code)
+	}
+}
+
+func TestMemberAccessChanged(t *testing.T) {
+	previous := &collectionmodels.Member{Email: "a@example.com", Team: "Art", Role: "manager", Name: "A"}
+	with := func(change func(m *collectionmodels.Member)) *collectionmodels.Member {
+		m := *previous
+		change(&m)
+		return &m
+	}
+
+	tests := []struct {
+		name     string
+		previous *collectionmodels.Member
+		updated  *collectionmodels.Member
+		want     bool
+	}{
+		{"name only", previous, with(func(m *collectionmodels.Member) { m.Name = "B" }), false},
+		{"email case only", previous, with(func(m *collectionmodels.Member) { m.Email = "A@example.com" }), false},
+		{"demoted", previous, with(func(m *collectionmodels.Member) { m.Role = "member" }), true},
+		{"moved team", previous, with(func(m *collectionmodels.Member) { m.Team = "Video" }), true},
+		{"new email", previous, with(func(m *collectionmodels.Member) { m.Email = "b@example.com" }), true},
+		{"previous state unknown", nil, previous, true},
+	}
+	for _, tt := range tests {
+		if got := memberAccessChanged(tt.previous, tt.updated); got != tt.want {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRevokeMemberSessions(t *testing.T) {
+	prev := sessions
+	t.Cleanup(func() { sessions = prev })
+	sessions = session.NewManager(session.NewMemoryStore(), 0, 0)
+
+	manager, err := sessions.Issue("a@example.com", []*db.TeamRole{{Team: "Art", Role: "manager"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := sessions.Issue("b@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revokeMemberSessions("a@example.com", "a@example.com", "")
+	if _, ok := sessions.Lookup(manager.Token); ok {
+		t.Error("demoted manager kept a session with the old roles")
+	}
+	if _, ok := sessions.Lookup(other.Token); !ok {
+		t.Error("another member's session was revoked")
+	}
+}
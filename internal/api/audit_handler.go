@@ -0,0 +1,186 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// masterTokenActor is the audit actor of calls made with SERVER_MASTER_TOKEN,
+// which carry no member email.
+const masterTokenActor = "master-token"
+
+// auditActor is who an audit entry of r is recorded for.
+func auditActor(r *http.Request) string {
+	if email := principalFrom(r).Email; email != "" {
+		return email
+	}
+	return masterTokenActor
+}
+
+// audited runs write and records, for the session's principal, the documents of
+// collection matched by filter before and after it. The write's error is
+// returned as is; a failure to record the entry is only logged.
+func audited(r *http.Request, entity, key, collection string, filter bson.M, write func() error) error {
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	before, err := collectionmodels.SnapshotDocuments(client, dbName, collection, filter, nil)
+	if err != nil {
+		return err
+	}
+	if err := write(); err != nil {
+		return err
+	}
+
+	entry := &collectionmodels.AuditEntry{
+		Actor:      auditActor(r),
+		Endpoint:   r.URL.Path,
+		Entity:     entity,
+		EntityKey:  key,
+		Collection: collection,
+		Before:     before,
+		At:         time.Now().UTC(),
+	}
+	entry.After, err = collectionmodels.SnapshotDocuments(client, dbName, collection, filter, collectionmodels.DocumentIDs(before))
+	if err == nil {
+		err = collectionmodels.InsertAuditEntry(client, dbName, os.Getenv("MONGODB_COLLECTION_AUDIT_LOG"), entry)
+	}
+	if err != nil {
+		log.Printf("audit %s %s %q by %s: error recording entry: %v", r.URL.Path, entity, key, entry.Actor, err)
+	}
+	return nil
+}
+
+// weeklyTargetKey is the audit entity key of a team's weekly target period.
+func weeklyTargetKey(team string, dateFrom, dateTo time.Time) string {
+	return team + " " + dateFrom.Format(time.DateOnly) + ".." + dateTo.Format(time.DateOnly)
+}
+
+// completedTaskKey is the audit entity key of a completed-task record.
+func completedTaskKey(task *collectionmodels.CompletedTask) string {
+	return task.TaskID + "/" + task.AssigneeID
+}
+
+// weeklyOrderKey is the audit entity key of a project's weekly order.
+func weeklyOrderKey(project string, startWeek time.Time) string {
+	return project + " " + startWeek.Format(time.DateOnly)
+}
+
+// HandleGetAuditLog lists audit entries, newest first. ?entity=, ?key= and
+// ?actor= filter, ?limit= caps the list (default 100).
+func HandleGetAuditLog(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := int64(100)
+	if v, err := strconv.ParseInt(q.Get("limit"), 10, 64); err == nil && v > 0 {
+		limit = v
+	}
+	entries, err := collectionmodels.GetAuditEntries(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_AUDIT_LOG"), q.Get("entity"), q.Get("key"), q.Get("actor"), limit)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// HandleRevertAuditEntry puts the documents changed by one audit entry back to
+// their state before it, provided nothing changed them since. The revert is
+// itself recorded. Body: {"id": "..."}.
+func HandleRevertAuditEntry(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(body.ID)
+	if err != nil {
+		http.Error(w, "Invalid ID format", http.StatusBadRequest)
+		return
+	}
+
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	auditColl := os.Getenv("MONGODB_COLLECTION_AUDIT_LOG")
+	entry, err := collectionmodels.GetAuditEntryByID(client, dbName, auditColl, id)
+	if err == mongo.ErrNoDocuments {
+		http.Error(w, "Audit entry not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+
+	revertID := primitive.NewObjectID()
+	claimed, err := collectionmodels.ClaimAuditRevert(client, dbName, auditColl, id, revertID)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	if !claimed {
+		http.Error(w, "Audit entry is already reverted", http.StatusConflict)
+		return
+	}
+	release := func() {
+		if err := collectionmodels.ReleaseAuditRevert(client, dbName, auditColl, id); err != nil {
+			log.Printf("audit %s: error releasing revert: %v", body.ID, err)
+		}
+	}
+
+	ids := collectionmodels.DocumentIDs(entry.Before, entry.After)
+	byID := bson.M{"_id": bson.M{"$in": ids}}
+	if err := collectionmodels.RestoreDocuments(client, dbName, entry.Collection, entry); err != nil {
+		release()
+		if err == collectionmodels.ErrAuditConflict {
+			http.Error(w, "Cannot revert: "+err.Error()+"; revert the later changes first", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	if entry.Entity == collectionmodels.AUDIT_ENTITY_MEMBER {
+		// The revert may restore another team or role; see HandleUpdateTeamMember.
+		var emails []string
+		for _, doc := range append(entry.Before, entry.After...) {
+			if email, ok := doc["email"].(string); ok {
+				emails = append(emails, email)
+			}
+		}
+		revokeMemberSessions(emails...)
+		db.InvalidateIdentities()
+	}
+
+	revert := &collectionmodels.AuditEntry{
+		ID:         revertID,
+		Actor:      auditActor(r),
+		Endpoint:   r.URL.Path,
+		Entity:     entry.Entity,
+		EntityKey:  entry.EntityKey,
+		Collection: entry.Collection,
+		Before:     entry.After,
+		At:         time.Now().UTC(),
+		RevertOf:   &entry.ID,
+	}
+	revert.After, err = collectionmodels.SnapshotDocuments(client, dbName, entry.Collection, byID, nil)
+	if err == nil {
+		err = collectionmodels.InsertAuditEntry(client, dbName, auditColl, revert)
+	}
+	if err != nil {
+		log.Printf("audit %s: error recording revert: %v", body.ID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revert)
+}
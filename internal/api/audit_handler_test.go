@@ -0,0 +1,38 @@
+package apihandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"performance-dashboard-backend/internal/session"
+)
+
+func TestAuditActor(t *testing.T) {
+	t.Setenv("SERVER_MASTER_TOKEN", "master")
+	prevSessions := sessions
+	t.Cleanup(func() { sessions = prevSessions })
+	sessions = session.NewManager(session.NewMemoryStore(), 0, 0)
+
+	actorOf := func(token string) string {
+		req := httptest.NewRequest(http.MethodPost, "/api/anything", nil)
+		req.Header.Set("Authorization", token)
+		principal, ok := authenticate(req)
+		if !ok {
+			t.Fatalf("token %q not accepted", token)
+		}
+		return auditActor(req.WithContext(context.WithValue(req.Context(), principalKey{}, principal)))
+	}
+
+	if got := actorOf("master"); got != masterTokenActor {
+		t.Errorf("master token recorded as %q, want %q", got, masterTokenActor)
+	}
+	s, err := sessions.Issue("a@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := actorOf(s.Token); got != "a@example.com" {
+		t.Errorf("session recorded as %q, want its member", got)
+	}
+}
@@ -0,0 +1,181 @@
+package apihandler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+	"time"
+
+	db "performance-dashboard-backend/internal/database"
+	"performance-dashboard-backend/internal/oidc"
+)
+
+const (
+	oidcStateTTL = 10 * time.Minute
+	// oidcStateCookie ties a pending login to the browser that started it, so a
+	// callback URL produced by someone else's login is refused (login CSRF).
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/auth/oidc"
+)
+
+var (
+	oidcProvider *oidc.Provider
+	oidcStates   = oidc.NewStateStore(oidcStateTTL)
+)
+
+// lookupOIDCMember reports whether email is a current member and their team
+// roles. Tests replace it to run the callback without a database.
+var lookupOIDCMember = func(email string) (bool, []*db.TeamRole, error) {
+	isInDatabase, err := db.IsEmailInDatabase(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), email)
+	if err != nil || !isInDatabase {
+		return false, nil, err
+	}
+	teamRoles, _ := db.GetMemberRoles(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), email)
+	return true, teamRoles, nil
+}
+
+// InitOIDC configures the OpenID Connect login flow from OIDC_* environment variables.
+// When the issuer is not configured the OIDC endpoints answer 503.
+func InitOIDC() {
+	config := oidc.ConfigFromEnv()
+	if !config.Enabled() {
+		log.Println("OIDC login disabled: OIDC_ISSUER, OIDC_CLIENT_ID or OIDC_REDIRECT_URL not set")
+		return
+	}
+	oidcProvider = oidc.NewProvider(config)
+}
+
+// emailLoginAllowed keeps the legacy email-only /login for local development.
+func emailLoginAllowed() bool {
+	return strings.EqualFold(os.Getenv("AUTH_ALLOW_EMAIL_LOGIN"), "true")
+}
+
+// setOIDCStateCookie stores state in an HttpOnly cookie scoped to the OIDC
+// endpoints; a negative maxAge clears it.
+func setOIDCStateCookie(w http.ResponseWriter, state string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(oidcProvider.Config().RedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// HandleOIDCLogin starts the authorization code flow and redirects to the issuer.
+// Pass ?format=json to receive the URL instead of a redirect (useful for SPAs);
+// the state cookie it sets must then reach the browser, so call it with credentials.
+func HandleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if oidcProvider == nil {
+		http.Error(w, "OIDC login is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	state, err1 := oidc.NewRandomString()
+	nonce, err2 := oidc.NewRandomString()
+	verifier, err3 := oidc.NewRandomString()
+	if err1 != nil || err2 != nil || err3 != nil {
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+
+	authURL, err := oidcProvider.AuthCodeURL(r.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Println("OIDC login error:", err)
+		http.Error(w, "Identity provider unavailable", http.StatusBadGateway)
+		return
+	}
+	oidcStates.Put(state, oidc.PendingLogin{Nonce: nonce, CodeVerifier: verifier, CreatedAt: time.Now()})
+	setOIDCStateCookie(w, state, int(oidcStateTTL.Seconds()))
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"url": authURL})
+		return
+	}
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// HandleOIDCCallback finishes the flow: exchanges the code, verifies the ID token,
+// maps the verified email to a Member and issues our own session token.
+func HandleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if oidcProvider == nil {
+		http.Error(w, "OIDC login is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	query := r.URL.Query()
+	// The state cookie is single use whatever the outcome.
+	setOIDCStateCookie(w, "", -1)
+	if errCode := query.Get("error"); errCode != "" {
+		http.Error(w, "Login failed: "+errCode, http.StatusUnauthorized)
+		return
+	}
+	state := query.Get("state")
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		http.Error(w, "Login state does not match this browser", http.StatusBadRequest)
+		return
+	}
+	pending, ok := oidcStates.Take(state)
+	if !ok {
+		http.Error(w, "Invalid or expired login state", http.StatusBadRequest)
+		return
+	}
+	code := query.Get("code")
+	if code == "" {
+		http.Error(w, "missing code", http.StatusBadRequest)
+		return
+	}
+
+	token, err := oidcProvider.Exchange(r.Context(), code, pending.CodeVerifier)
+	if err != nil {
+		log.Println("OIDC code exchange error:", err)
+		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		return
+	}
+	claims, err := oidcProvider.VerifyIDToken(r.Context(), token.IDToken, pending.Nonce)
+	if err != nil {
+		log.Println("OIDC id token rejected:", err)
+		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		return
+	}
+	if claims.Email == "" || !claims.IsEmailVerified() {
+		http.Error(w, "Email not verified by identity provider", http.StatusUnauthorized)
+		return
+	}
+	email := strings.ToLower(claims.Email)
+
+	isMember, teamRoles, err := lookupOIDCMember(email)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !isMember {
+		log.Println("OIDC login for unknown member:", email)
+		http.Error(w, "No member registered for "+email, http.StatusForbidden)
+		return
+	}
+
+	s, err := sessions.Issue(email, teamRoles)
+	if err != nil {
+		log.Println("Error creating session:", err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	// Hand the token to the frontend in the URL fragment so it never reaches server logs.
+	if redirect := os.Getenv("OIDC_POST_LOGIN_REDIRECT"); redirect != "" {
+		fragment := neturl.Values{}
+		fragment.Set("token", s.Token)
+		fragment.Set("expires_at", s.ExpiresAt.Format(time.RFC3339))
+		http.Redirect(w, r, redirect+"#"+fragment.Encode(), http.StatusFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"token": s.Token, "expires_at": s.ExpiresAt})
+}
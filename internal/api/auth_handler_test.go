@@ -0,0 +1,163 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"testing"
+
+	db "performance-dashboard-backend/internal/database"
+	"performance-dashboard-backend/internal/oidc"
+	"performance-dashboard-backend/internal/oidc/oidctest"
+	"performance-dashboard-backend/internal/session"
+)
+
+// setupOIDC points the OIDC handlers at a stand-in issuer, an in-memory session
+// store and a single member, restoring the globals afterwards.
+func setupOIDC(t *testing.T) *oidctest.Issuer {
+	t.Helper()
+	iss := oidctest.NewIssuer("dashboard")
+	t.Cleanup(iss.Close)
+
+	prevProvider, prevSessions, prevLookup := oidcProvider, sessions, lookupOIDCMember
+	t.Cleanup(func() { oidcProvider, sessions, lookupOIDCMember = prevProvider, prevSessions, prevLookup })
+	oidcProvider = oidc.NewProvider(oidc.Config{Issuer: iss.URL, ClientID: "dashboard", RedirectURL: "http://localhost/auth/oidc/callback"})
+	sessions = session.NewManager(session.NewMemoryStore(), 0, 0)
+	lookupOIDCMember = func(email string) (bool, []*db.TeamRole, error) {
+		return email == "a@example.com", nil, nil
+	}
+	t.Setenv("OIDC_POST_LOGIN_REDIRECT", "")
+	return iss
+}
+
+// startLogin runs /auth/oidc/login and returns the state cookie and the
+// state and nonce sent to the issuer.
+func startLogin(t *testing.T) (*http.Cookie, string, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	HandleOIDCLogin(rec, httptest.NewRequest(http.MethodGet, "/auth/oidc/login?format=json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: %d %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		URL string `json:"url"`
+	}
+	json.NewDecoder(rec.Body).Decode(&body)
+	authURL, err := neturl.Parse(body.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == oidcStateCookie {
+			cookie = c
+		}
+	}
+	if cookie == nil {
+		t.Fatal("login set no state cookie")
+	}
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != oidcCookiePath {
+		t.Fatalf("state cookie %+v", cookie)
+	}
+	state := authURL.Query().Get("state")
+	if cookie.Value != state {
+		t.Fatalf("cookie %q does not hold the state %q", cookie.Value, state)
+	}
+	return cookie, state, authURL.Query().Get("nonce")
+}
+
+func callback(state, code string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/auth/oidc/callback?"+neturl.Values{"state": {state}, "code": {code}}.Encode(), nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	HandleOIDCCallback(rec, req)
+	return rec
+}
+
+func clearsStateCookie(rec *httptest.ResponseRecorder) bool {
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == oidcStateCookie && c.MaxAge < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOIDCCallback(t *testing.T) {
+	iss := setupOIDC(t)
+	cookie, state, nonce := startLogin(t)
+
+	rec := callback(state, iss.IssueCode(iss.Sign(iss.Claims("a@example.com", nonce))), cookie)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("callback: %d %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Token string `json:"token"`
+	}
+	json.NewDecoder(rec.Body).Decode(&body)
+	s, ok := sessions.Lookup(body.Token)
+	if !ok || s.Email != "a@example.com" {
+		t.Fatalf("no session for the returned token: %+v", s)
+	}
+	if !clearsStateCookie(rec) {
+		t.Error("callback did not clear the state cookie")
+	}
+
+	// The state is single use.
+	rec = callback(state, iss.IssueCode(iss.Sign(iss.Claims("a@example.com", nonce))), cookie)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("replayed state: %d, want 400", rec.Code)
+	}
+}
+
+func TestOIDCCallbackRejects(t *testing.T) {
+	iss := setupOIDC(t)
+
+	t.Run("no state cookie", func(t *testing.T) {
+		_, state, nonce := startLogin(t)
+		rec := callback(state, iss.IssueCode(iss.Sign(iss.Claims("a@example.com", nonce))), nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%d, want 400", rec.Code)
+		}
+	})
+	t.Run("state of another browser", func(t *testing.T) {
+		// The attacker starts a login and sends the victim its callback URL;
+		// the victim's browser carries its own state cookie.
+		_, attackerState, attackerNonce := startLogin(t)
+		victimCookie, _, _ := startLogin(t)
+		rec := callback(attackerState, iss.IssueCode(iss.Sign(iss.Claims("a@example.com", attackerNonce))), victimCookie)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%d, want 400", rec.Code)
+		}
+		if !clearsStateCookie(rec) {
+			t.Error("rejected callback did not clear the state cookie")
+		}
+	})
+	t.Run("nonce mismatch", func(t *testing.T) {
+		cookie, state, _ := startLogin(t)
+		rec := callback(state, iss.IssueCode(iss.Sign(iss.Claims("a@example.com", "other-nonce"))), cookie)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("%d, want 401", rec.Code)
+		}
+	})
+	t.Run("bad signature", func(t *testing.T) {
+		cookie, state, nonce := startLogin(t)
+		other := oidctest.NewIssuer("dashboard")
+		defer other.Close()
+		rec := callback(state, iss.IssueCode(oidctest.SignWith(other.Key, oidctest.KeyID, iss.Claims("a@example.com", nonce))), cookie)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("%d, want 401", rec.Code)
+		}
+	})
+	t.Run("unknown member", func(t *testing.T) {
+		cookie, state, nonce := startLogin(t)
+		rec := callback(state, iss.IssueCode(iss.Sign(iss.Claims("stranger@example.com", nonce))), cookie)
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("%d, want 403", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,230 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const TRANSITION_EVENT_CHANGE_REQUEST = "change_request"
+
+// submitChangeRequest files a pending change request for task, recording the
+// stored record it would replace.
+func submitChangeRequest(principal *Principal, task *collectionmodels.CompletedTask, reason string) (*collectionmodels.ChangeRequest, error) {
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	current, err := collectionmodels.GetCompletedTask(client, dbName, os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), task.TaskID, task.AssigneeID, task.IsConcept())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	request := &collectionmodels.ChangeRequest{
+		TaskID:    task.TaskID,
+		Team:      task.Team,
+		Proposed:  *task,
+		Current:   current,
+		Reason:    reason,
+		Requester: principal.Email,
+		Status:    collectionmodels.CHANGE_REQUEST_PENDING,
+		CreatedAt: now,
+		Trail: []collectionmodels.ChangeRequestDecision{
+			{Action: collectionmodels.CHANGE_ACTION_SUBMITTED, Actor: principal.Email, Comment: reason, At: now},
+		},
+	}
+	if err := collectionmodels.InsertChangeRequest(client, dbName, os.Getenv("MONGODB_COLLECTION_CHANGE_REQUEST"), request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// HandleGetChangeRequests lists change requests, newest first. Managers see the
+// requests of the teams they manage (admins every team, or ?team=), everyone
+// else their own. ?status= filters, ?limit= caps the list (default 100).
+func HandleGetChangeRequests(w http.ResponseWriter, r *http.Request) {
+	principal := principalFrom(r)
+	team := r.URL.Query().Get("team")
+	limit := int64(100)
+	if v, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64); err == nil && v > 0 {
+		limit = v
+	}
+
+	var teams []string
+	requester := ""
+	switch {
+	case team != "":
+		if !principal.IsManagerOf(team) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		teams = []string{team}
+	case principal.IsAdmin():
+	case len(principal.ManagedTeams()) > 0:
+		teams = principal.ManagedTeams()
+	default:
+		requester = principal.Email
+	}
+
+	requests, err := collectionmodels.GetChangeRequests(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_CHANGE_REQUEST"), teams, r.URL.Query().Get("status"), requester, limit)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+// canDecideChangeRequest reports whether principal manages every team the
+// request touches: the proposed team and, for an edit, the team of the record
+// it replaces, so a record cannot be moved out of a team behind its manager.
+func canDecideChangeRequest(principal *Principal, request *collectionmodels.ChangeRequest) bool {
+	if !principal.IsManagerOf(request.Team) {
+		return false
+	}
+	return request.Current == nil || principal.IsManagerOf(request.Current.Team)
+}
+
+// HandleDecideChangeRequest approves or rejects a pending change request of a team
+// the caller manages. Approving writes the proposed record to completed-task.
+// Body: {"id": "...", "action": "approve" | "reject", "comment": "..."}.
+func HandleDecideChangeRequest(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ID      string `json:"id"`
+		Action  string `json:"action"`
+		Comment string `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(body.ID)
+	if err != nil {
+		http.Error(w, "Invalid ID format", http.StatusBadRequest)
+		return
+	}
+	var status, action string
+	switch body.Action {
+	case "approve":
+		status, action = collectionmodels.CHANGE_REQUEST_APPROVED, collectionmodels.CHANGE_ACTION_APPROVED
+	case "reject":
+		status, action = collectionmodels.CHANGE_REQUEST_REJECTED, collectionmodels.CHANGE_ACTION_REJECTED
+	default:
+		http.Error(w, "action must be approve or reject", http.StatusBadRequest)
+		return
+	}
+
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	collName := os.Getenv("MONGODB_COLLECTION_CHANGE_REQUEST")
+	request, err := collectionmodels.GetChangeRequestByID(client, dbName, collName, id)
+	if err == mongo.ErrNoDocuments {
+		http.Error(w, "Change request not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	principal := principalFrom(r)
+	if !canDecideChangeRequest(principal, request) {
+		http.Error(w, "Forbidden: the request must be decided by a manager of every team it touches", http.StatusForbidden)
+		return
+	}
+	if !principal.IsAdmin() && strings.EqualFold(request.Requester, principal.Email) {
+		http.Error(w, "A change request cannot be decided by its requester", http.StatusForbidden)
+		return
+	}
+
+	decision := collectionmodels.ChangeRequestDecision{Action: action, Actor: principal.Email, Comment: body.Comment, At: time.Now().UTC()}
+	decided, err := collectionmodels.DecideChangeRequest(client, dbName, collName, id, status, decision)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	if !decided {
+		http.Error(w, "Change request is already "+request.Status, http.StatusConflict)
+		return
+	}
+
+	if status == collectionmodels.CHANGE_REQUEST_APPROVED {
+		if err := applyChangeRequest(r, request); err != nil {
+			failure := collectionmodels.ChangeRequestDecision{Action: collectionmodels.CHANGE_ACTION_FAILED, Actor: principal.Email, Comment: err.Error(), At: time.Now().UTC()}
+			if reopenErr := collectionmodels.ReopenChangeRequest(client, dbName, collName, id, failure); reopenErr != nil {
+				log.Printf("change request %s: error reopening after failed approval: %v", body.ID, reopenErr)
+			}
+			http.Error(w, "Database error: "+err.Error(), 500)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"id": body.ID, "status": status})
+}
+
+// applyChangeRequest writes an approved request's record, audited, and its
+// transition. The record replaced is read again, and must still be of a team the
+// approver manages.
+func applyChangeRequest(r *http.Request, request *collectionmodels.ChangeRequest) error {
+	approver := principalFrom(r)
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	collName := os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK")
+	proposed := request.Proposed
+
+	current, err := collectionmodels.GetCompletedTask(client, dbName, collName, proposed.TaskID, proposed.AssigneeID, proposed.IsConcept())
+	if err != nil {
+		return err
+	}
+	if current != nil && !approver.IsManagerOf(current.Team) {
+		return fmt.Errorf("task %s is now a record of team %s, which %s does not manage", proposed.TaskID, current.Team, approver.Email)
+	}
+	transition := &collectionmodels.TaskTransition{
+		TaskID:   proposed.TaskID,
+		Reason:   request.Reason,
+		Event:    TRANSITION_EVENT_CHANGE_REQUEST,
+		EventID:  request.ID.Hex(),
+		Occurred: time.Now().UTC(),
+	}
+	if current == nil {
+		filter := bson.M{"id": proposed.TaskID, "assignee_id": proposed.AssigneeID, "team": proposed.Team}
+		err := audited(r, collectionmodels.AUDIT_ENTITY_COMPLETED_TASK, completedTaskKey(&proposed), collName, filter, func() error {
+			return collectionmodels.UpsertCompletedTask(client, dbName, collName, &proposed, false)
+		})
+		if err != nil {
+			return fmt.Errorf("insert task %s: %w", proposed.TaskID, err)
+		}
+		transition.Action = collectionmodels.TRANSITION_CREATED
+	} else {
+		err := audited(r, collectionmodels.AUDIT_ENTITY_COMPLETED_TASK, completedTaskKey(current), collName, bson.M{"_id": current.ID}, func() error {
+			return collectionmodels.ReplaceCompletedTask(client, dbName, collName, current.ID, &proposed)
+		})
+		if err != nil {
+			return fmt.Errorf("update task %s: %w", proposed.TaskID, err)
+		}
+		proposed.ID = current.ID
+		transition.Action = collectionmodels.TRANSITION_UPDATED
+		if current.Void {
+			transition.Action = collectionmodels.TRANSITION_RESTORED
+		}
+		transition.Before = current
+	}
+	transition.After = &proposed
+
+	if err := collectionmodels.InsertTaskTransitions(client, dbName, os.Getenv("MONGODB_COLLECTION_TASK_TRANSITION"), []*collectionmodels.TaskTransition{transition}); err != nil {
+		// The record is written; a missing history entry is not worth failing the approval.
+		log.Printf("change request %s: error recording transition: %v", request.ID.Hex(), err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package apihandler
+
+import (
+	"testing"
+
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+)
+
+func TestCanDecideChangeRequest(t *testing.T) {
+	admin := &Principal{Email: "admin@example.com", TeamRoles: []*db.TeamRole{{Role: "admin"}}}
+	addition := &collectionmodels.ChangeRequest{Team: "Art"}
+	sameTeam := &collectionmodels.ChangeRequest{Team: "Art", Current: &collectionmodels.CompletedTask{Team: "Art"}}
+	moveOut := &collectionmodels.ChangeRequest{Team: "Art", Current: &collectionmodels.CompletedTask{Team: "Video"}}
+
+	tests := []struct {
+		name      string
+		principal *Principal
+		request   *collectionmodels.ChangeRequest
+		want      bool
+	}{
+		{"addition by the team's manager", managerOf("Art"), addition, true},
+		{"addition by another team's manager", managerOf("Video"), addition, false},
+		{"edit within the team", managerOf("Art"), sameTeam, true},
+		{"move out of a team by the destination's manager", managerOf("Art"), moveOut, false},
+		{"move out of a team by the source's manager", managerOf("Video"), moveOut, false},
+		{"move by a manager of both teams", managerOf("Art", "Video"), moveOut, true},
+		{"move by an admin", admin, moveOut, true},
+	}
+	for _, tt := range tests {
+		if got := canDecideChangeRequest(tt.principal, tt.request); got != tt.want {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
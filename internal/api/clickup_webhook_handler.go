@@ -0,0 +1,117 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"performance-dashboard-backend/internal/clickup"
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+)
+
+const (
+	WEBHOOK_KIND_TASK    = "task"
+	WEBHOOK_KIND_CONCEPT = "concept"
+
+	maxWebhookBodyBytes = 1 << 20
+)
+
+var webhookSecrets clickup.WebhookSecrets
+
+func InitClickUpWebhooks() {
+	webhookSecrets = clickup.LoadWebhookSecretsFromEnv()
+	if len(webhookSecrets) == 0 {
+		log.Println("ClickUp webhook: CLICKUP_WEBHOOK_SECRETS is empty, all deliveries will be rejected")
+	}
+	if err := collectionmodels.EnsureWebhookEventIndexes(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEBHOOK_EVENT")); err != nil {
+		log.Println("ClickUp webhook: error creating webhook event indexes:", err)
+	}
+}
+
+func HandleClickUpWebhookDoneTask(w http.ResponseWriter, r *http.Request) {
+	handleClickUpWebhook(w, r, WEBHOOK_KIND_TASK)
+}
+
+func HandleClickUpWebhookDoneConcept(w http.ResponseWriter, r *http.Request) {
+	handleClickUpWebhook(w, r, WEBHOOK_KIND_CONCEPT)
+}
+
+// handleClickUpWebhook authenticates a ClickUp delivery, drops replays and queues
+// the event in the inbox; the webhook workers do the actual processing.
+func handleClickUpWebhook(w http.ResponseWriter, r *http.Request, kind string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	payload, err := clickup.ParseVerifiedWebhook(body, r.Header.Get("X-Signature"), webhookSecrets, clickup.WebhookMaxAge())
+	switch {
+	case errors.Is(err, clickup.ErrUnknownWebhook), errors.Is(err, clickup.ErrInvalidSignature):
+		log.Printf("ClickUp webhook: rejected delivery: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	case err != nil:
+		log.Printf("ClickUp webhook: rejected delivery: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event := &collectionmodels.WebhookEvent{
+		EventID:    payload.EventID(),
+		WebhookID:  payload.WebhookID,
+		Event:      payload.Event,
+		TaskID:     payload.TaskID,
+		Kind:       kind,
+		Payload:    string(body),
+		ReceivedAt: time.Now().UTC(),
+	}
+	isNew, err := collectionmodels.InsertWebhookEvent(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEBHOOK_EVENT"), event)
+	if err != nil {
+		log.Printf("ClickUp webhook: error queueing event for task %s: %v", payload.TaskID, err)
+		http.Error(w, "failed to record event", http.StatusInternalServerError)
+		return
+	}
+	if !isNew {
+		log.Printf("ClickUp webhook: duplicate event %s ignored", event.EventID)
+		writeWebhookStatus(w, http.StatusOK, payload.TaskID, "duplicate")
+		return
+	}
+
+	notifyWebhookWorkers()
+	writeWebhookStatus(w, http.StatusAccepted, payload.TaskID, "queued")
+}
+
+func writeWebhookStatus(w http.ResponseWriter, code int, taskID, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"task_id": taskID,
+		"status":  status,
+	})
+}
+
+// HandleGetTaskTransitions lists every change the webhooks applied to a task's records.
+func HandleGetTaskTransitions(w http.ResponseWriter, r *http.Request) {
+	taskID := r.URL.Query().Get("task_id")
+	if taskID == "" {
+		http.Error(w, "missing task_id", http.StatusBadRequest)
+		return
+	}
+	transitions, err := collectionmodels.GetTaskTransitions(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TASK_TRANSITION"), taskID)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transitions)
+}
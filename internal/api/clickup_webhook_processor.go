@@ -0,0 +1,222 @@
+package apihandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"performance-dashboard-backend/internal/clickup"
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/tasksource"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	WEBHOOK_STATUS_SAVED     = "saved"
+	WEBHOOK_STATUS_UNCHANGED = "unchanged"
+	WEBHOOK_STATUS_IGNORED   = "ignored"
+)
+
+// processClickUpWebhook recomputes the completed-task records of the task an event
+// refers to, so reopening, deleting, reassigning or editing the scored fields of a
+// task in ClickUp is reflected in the stored points. Events for tasks no team
+// syncs are ignored, after voiding any records the task still has.
+func processClickUpWebhook(event *collectionmodels.WebhookEvent) (string, error) {
+	desired, voidReason, err := desiredCompletedTask(event)
+	var rejection *tasksource.RejectionError
+	if errors.As(err, &rejection) {
+		clickup.RecordRejections(collectionmodels.REJECTION_ORIGIN_WEBHOOK, []collectionmodels.TaskRejection{rejection.Rejection})
+	}
+	if err != nil {
+		return "", err
+	}
+	// Accepted, reopened or deleted: whatever was rejected before no longer needs fixing.
+	clickup.ResolveRejections([]string{event.TaskID})
+
+	transitions, err := reconcileCompletedTask(event, desired, voidReason, false)
+	if err != nil {
+		return "", err
+	}
+	if len(transitions) > 0 {
+		if err := collectionmodels.InsertTaskTransitions(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TASK_TRANSITION"), transitions); err != nil {
+			// The records are already reconciled; losing the history entry is not worth a retry.
+			log.Printf("ClickUp webhook: error recording transitions for task %s: %v", event.TaskID, err)
+		}
+	}
+	switch {
+	case voidReason == collectionmodels.VOID_REASON_UNTRACKED, len(transitions) == 0 && desired == nil:
+		return WEBHOOK_STATUS_IGNORED, nil
+	case len(transitions) == 0:
+		return WEBHOOK_STATUS_UNCHANGED, nil
+	}
+	return WEBHOOK_STATUS_SAVED, nil
+}
+
+// planClickUpWebhook returns the transitions processing the event would apply, without writing.
+func planClickUpWebhook(event *collectionmodels.WebhookEvent) ([]*collectionmodels.TaskTransition, error) {
+	desired, voidReason, err := desiredCompletedTask(event)
+	if err != nil {
+		return nil, err
+	}
+	return reconcileCompletedTask(event, desired, voidReason, true)
+}
+
+// desiredCompletedTask is the record the task should have now, or nil with the
+// reason its records should be voided. Only a task the tracker data rules out is
+// a permanent failure.
+func desiredCompletedTask(event *collectionmodels.WebhookEvent) (*collectionmodels.CompletedTask, string, error) {
+	if event.Event == clickup.EVENT_TASK_DELETED {
+		return nil, collectionmodels.VOID_REASON_DELETED, nil
+	}
+
+	task, err := clickup.DefaultClient().FetchSingleTask(context.Background(), event.TaskID)
+	if clickup.IsNotFound(err) {
+		// Deleted (or moved out of reach) before we got to the event.
+		return nil, collectionmodels.VOID_REASON_DELETED, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch task %s: %w", event.TaskID, err)
+	}
+
+	concept := event.Kind == WEBHOOK_KIND_CONCEPT
+	done := clickup.IsTaskDone(task)
+	if concept {
+		done = clickup.IsConceptDone(task)
+	}
+	if !done {
+		return nil, collectionmodels.VOID_REASON_REOPENED, nil
+	}
+
+	var desired *collectionmodels.CompletedTask
+	if concept {
+		desired, err = clickup.ProcessWebhookConcept(task)
+	} else {
+		desired, err = clickup.ProcessWebhookTask(task)
+	}
+	if errors.Is(err, clickup.ErrUntrackedTask) {
+		return nil, collectionmodels.VOID_REASON_UNTRACKED, nil
+	}
+	var rejection *tasksource.RejectionError
+	if errors.As(err, &rejection) {
+		return nil, "", &permanentError{fmt.Errorf("process task %s: %w", event.TaskID, err)}
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("process task %s: %w", event.TaskID, err)
+	}
+	return desired, "", nil
+}
+
+// reconcileCompletedTask makes the stored records of a task match desired: the
+// record of desired's assignee is created, updated or restored, every other active
+// record is voided. A nil desired voids all active records with voidReason. With
+// dryRun the transitions are computed but nothing is written.
+func reconcileCompletedTask(event *collectionmodels.WebhookEvent, desired *collectionmodels.CompletedTask, voidReason string, dryRun bool) ([]*collectionmodels.TaskTransition, error) {
+	concept := event.Kind == WEBHOOK_KIND_CONCEPT
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	collName := os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK")
+
+	existing, err := collectionmodels.GetCompletedTasksByTaskID(client, dbName, collName, collectionmodels.SOURCE_CLICKUP, event.TaskID, concept)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	newTransition := func(action, reason string, before, after *collectionmodels.CompletedTask) *collectionmodels.TaskTransition {
+		return &collectionmodels.TaskTransition{
+			TaskID:   event.TaskID,
+			Action:   action,
+			Reason:   reason,
+			Event:    event.Event,
+			EventID:  event.EventID,
+			Before:   before,
+			After:    after,
+			Occurred: now,
+		}
+	}
+
+	var transitions []*collectionmodels.TaskTransition
+	matched := false
+	for i := range existing {
+		record := existing[i]
+		if desired != nil && !matched && record.AssigneeID == desired.AssigneeID {
+			matched = true
+			if !record.Void {
+				if sameScoredFields(&record, desired) {
+					continue
+				}
+				// An edit on a live record must not move it to the week the edit happened in.
+				desired.DoneDate = record.DoneDate
+			}
+			if err := replaceCompletedTask(dryRun, client, dbName, collName, record.ID, desired); err != nil {
+				return transitions, err
+			}
+			after := *desired
+			after.ID = record.ID
+			action := collectionmodels.TRANSITION_UPDATED
+			if record.Void {
+				action = collectionmodels.TRANSITION_RESTORED
+			}
+			transitions = append(transitions, newTransition(action, "", &record, &after))
+			continue
+		}
+
+		if record.Void {
+			continue
+		}
+		reason := voidReason
+		if desired != nil {
+			reason = collectionmodels.VOID_REASON_REASSIGNED
+		}
+		if err := voidCompletedTask(dryRun, client, dbName, collName, record.ID, reason, now); err != nil {
+			return transitions, err
+		}
+		after := record
+		after.Void, after.VoidReason, after.VoidedAt = true, reason, &now
+		transitions = append(transitions, newTransition(collectionmodels.TRANSITION_VOIDED, reason, &record, &after))
+	}
+
+	if desired != nil && !matched {
+		if err := upsertCompletedTask(dryRun, client, dbName, collName, desired); err != nil {
+			return transitions, err
+		}
+		transitions = append(transitions, newTransition(collectionmodels.TRANSITION_CREATED, "", nil, desired))
+	}
+	return transitions, nil
+}
+
+func sameScoredFields(a, b *collectionmodels.CompletedTask) bool {
+	for _, field := range collectionmodels.ChangedFields(a, b) {
+		if field != "done_date" && field != "assignee_id" {
+			return false
+		}
+	}
+	return true
+}
+
+func replaceCompletedTask(dryRun bool, client *mongo.Client, dbName, collName string, id primitive.ObjectID, task *collectionmodels.CompletedTask) error {
+	if dryRun {
+		return nil
+	}
+	return collectionmodels.ReplaceCompletedTask(client, dbName, collName, id, task)
+}
+
+func voidCompletedTask(dryRun bool, client *mongo.Client, dbName, collName string, id primitive.ObjectID, reason string, at time.Time) error {
+	if dryRun {
+		return nil
+	}
+	return collectionmodels.VoidCompletedTask(client, dbName, collName, id, reason, at)
+}
+
+func upsertCompletedTask(dryRun bool, client *mongo.Client, dbName, collName string, task *collectionmodels.CompletedTask) error {
+	if dryRun {
+		return nil
+	}
+	return collectionmodels.UpsertCompletedTask(client, dbName, collName, task, false)
+}
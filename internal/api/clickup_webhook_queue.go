@@ -0,0 +1,181 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"performance-dashboard-backend/internal/clickup"
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultWebhookWorkers     = 4
+	defaultWebhookMaxAttempts = 8
+	webhookRetryBase          = 30 * time.Second
+	webhookRetryMax           = time.Hour
+	webhookLease              = 2 * time.Minute
+	webhookIdlePoll           = 5 * time.Second
+	webhookTaskBusyDelay      = 5 * time.Second
+)
+
+// permanentError marks a processing failure that retrying will not fix, such as a
+// task with no difficulty set. The event goes straight to the dead-letter list.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// webhookWake nudges an idle worker as soon as a new event is queued.
+var webhookWake = make(chan struct{}, 1)
+
+func notifyWebhookWorkers() {
+	select {
+	case webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+// StartWebhookWorkers runs WEBHOOK_WORKERS goroutines that drain the webhook inbox.
+func StartWebhookWorkers() {
+	workers := envInt("WEBHOOK_WORKERS", defaultWebhookWorkers)
+	for i := 0; i < workers; i++ {
+		go webhookWorker()
+	}
+	log.Printf("ClickUp webhook: started %d workers", workers)
+}
+
+func webhookWorker() {
+	for {
+		processed, err := processNextWebhookEvent()
+		if err != nil {
+			log.Println("ClickUp webhook worker:", err)
+		}
+		if processed {
+			continue
+		}
+		select {
+		case <-webhookWake:
+		case <-time.After(webhookIdlePoll):
+		}
+	}
+}
+
+// processNextWebhookEvent claims one due event and processes it. It reports false
+// when the inbox had nothing due. Events of a task are processed one at a time:
+// an event whose task is leased by another worker is put back for a moment.
+func processNextWebhookEvent() (bool, error) {
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	collName := os.Getenv("MONGODB_COLLECTION_WEBHOOK_EVENT")
+	leaseColl := os.Getenv("MONGODB_COLLECTION_WEBHOOK_TASK_LEASE")
+
+	claimedAt := time.Now().UTC()
+	event, err := collectionmodels.ClaimWebhookEvent(client, dbName, collName, claimedAt, webhookLease)
+	if err != nil || event == nil {
+		return false, err
+	}
+	leased, err := collectionmodels.AcquireWebhookTaskLease(client, dbName, leaseColl, event.TaskID, event.ID, claimedAt, webhookLease)
+	if err != nil || !leased {
+		if deferErr := collectionmodels.DeferWebhookEvent(client, dbName, collName, event.ID, claimedAt.Add(webhookTaskBusyDelay)); deferErr != nil && err == nil {
+			err = deferErr
+		}
+		return true, err
+	}
+	defer func() {
+		if err := collectionmodels.ReleaseWebhookTaskLease(client, dbName, leaseColl, event.TaskID, event.ID); err != nil {
+			log.Printf("ClickUp webhook: error releasing lease of task %s: %v", event.TaskID, err)
+		}
+	}()
+
+	status, procErr := processClickUpWebhook(event)
+	now := time.Now().UTC()
+	if procErr == nil {
+		log.Printf("ClickUp webhook: %s for task %s: %s", event.Event, event.TaskID, status)
+		// Keep the entry a little longer than the age window so a replay is always caught.
+		return true, collectionmodels.MarkWebhookEventDone(client, dbName, collName, event.ID, now, now.Add(clickup.WebhookMaxAge()+time.Hour))
+	}
+
+	var permanent *permanentError
+	dead := errors.As(procErr, &permanent) || event.Attempts >= envInt("WEBHOOK_MAX_ATTEMPTS", defaultWebhookMaxAttempts)
+	if dead {
+		log.Printf("ClickUp webhook: event %s moved to dead letters after %d attempts: %v", event.EventID, event.Attempts, procErr)
+	} else {
+		log.Printf("ClickUp webhook: event %s attempt %d failed: %v", event.EventID, event.Attempts, procErr)
+	}
+	return true, collectionmodels.MarkWebhookEventFailed(client, dbName, collName, event.ID, procErr.Error(), now.Add(webhookBackoff(event.Attempts)), dead)
+}
+
+// webhookBackoff doubles the delay after each failed attempt: 30s, 1m, 2m, ... up to an hour.
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempts && delay < webhookRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookRetryMax)
+}
+
+/// ==== Dead letters ====
+
+func HandleGetWebhookDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit := int64(100)
+	if v, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64); err == nil && v > 0 {
+		limit = v
+	}
+	events, err := collectionmodels.GetDeadWebhookEvents(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEBHOOK_EVENT"), limit)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// HandleReplayWebhookEvents requeues dead events: body {"ids": [...]} or {"all": true}.
+func HandleReplayWebhookEvents(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		IDs []string `json:"ids"`
+		All bool     `json:"all"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if len(body.IDs) == 0 && !body.All {
+		http.Error(w, "ids or all is required", http.StatusBadRequest)
+		return
+	}
+
+	var ids []primitive.ObjectID
+	for _, hex := range body.IDs {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			http.Error(w, "Invalid ID: "+hex, http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	n, err := collectionmodels.ReplayDeadWebhookEvents(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_WEBHOOK_EVENT"), ids, time.Now().UTC())
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	notifyWebhookWorkers()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"replayed": n})
+}
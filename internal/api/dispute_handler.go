@@ -0,0 +1,416 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/scoring"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	TRANSITION_EVENT_DISPUTE = "dispute"
+
+	defaultDisputeSLA = 72 * time.Hour
+)
+
+// DisputeView is a dispute with its SLA aging.
+type DisputeView struct {
+	collectionmodels.Dispute
+	AgeHours float64   `json:"age_hours"`
+	DueAt    time.Time `json:"due_at"`
+	Overdue  bool      `json:"overdue"`
+}
+
+// DisputeSLA reads DISPUTE_SLA (a Go duration), the time managers have to resolve a dispute.
+func DisputeSLA() time.Duration {
+	if v := os.Getenv("DISPUTE_SLA"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("Invalid DISPUTE_SLA, using default: %s", v)
+	}
+	return defaultDisputeSLA
+}
+
+func newDisputeView(d collectionmodels.Dispute, sla time.Duration, now time.Time) DisputeView {
+	age := d.Age(now)
+	return DisputeView{
+		Dispute:  d,
+		AgeHours: age.Hours(),
+		DueAt:    d.CreatedAt.Add(sla),
+		Overdue:  age > sla,
+	}
+}
+
+// HandleOpenDispute opens a dispute on one of the caller's completed task records.
+// Body: {"record_id": "...", "proposed_level": 3, "proposed_tool": [1, 4],
+// "comment": "..."}; record_id is the record's id, as a task's concept and team
+// records share the task id, and at least one of proposed_level / proposed_tool
+// is required.
+func HandleOpenDispute(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RecordID      string `json:"record_id"`
+		ProposedLevel *int   `json:"proposed_level"`
+		ProposedTool  []int  `json:"proposed_tool"`
+		Comment       string `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	principal := principalFrom(r)
+	if body.RecordID == "" || strings.TrimSpace(body.Comment) == "" {
+		http.Error(w, "record_id and comment are required", http.StatusBadRequest)
+		return
+	}
+	recordID, err := primitive.ObjectIDFromHex(body.RecordID)
+	if err != nil {
+		http.Error(w, "Invalid record_id format", http.StatusBadRequest)
+		return
+	}
+	if body.ProposedLevel == nil && len(body.ProposedTool) == 0 {
+		http.Error(w, "proposed_level or proposed_tool is required", http.StatusBadRequest)
+		return
+	}
+
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	task, err := collectionmodels.GetCompletedTaskByID(client, dbName, os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), recordID)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	if task == nil || task.Void {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+	if !principal.IsAdmin() && !isTaskContributor(task, principal.Email) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	rules, err := scoring.LoadRules(client, dbName)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	if err := validateDisputeProposal(rules, task, body.ProposedLevel, body.ProposedTool); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	collName := os.Getenv("MONGODB_COLLECTION_DISPUTE")
+	existing, err := collectionmodels.GetOpenDisputeByRecord(client, dbName, collName, task.ID)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	if existing != nil {
+		http.Error(w, "Task already has an open dispute: "+existing.ID.Hex(), http.StatusConflict)
+		return
+	}
+
+	now := time.Now().UTC()
+	dispute := &collectionmodels.Dispute{
+		RecordID:      task.ID,
+		TaskID:        task.TaskID,
+		TaskName:      task.TaskName,
+		AssigneeID:    task.AssigneeID,
+		Team:          task.Team,
+		Level:         task.Level,
+		Tool:          task.Tool,
+		ProposedLevel: body.ProposedLevel,
+		ProposedTool:  body.ProposedTool,
+		Opener:        principal.Email,
+		Status:        collectionmodels.DISPUTE_OPEN,
+		CreatedAt:     now,
+		Comments: []collectionmodels.DisputeComment{
+			{Author: principal.Email, Text: body.Comment, At: now},
+		},
+	}
+	if err := collectionmodels.InsertDispute(client, dbName, collName, dispute); err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newDisputeView(*dispute, DisputeSLA(), now))
+}
+
+// HandleGetDisputes lists disputes with their SLA aging, oldest first. Managers
+// see the disputes of the teams they manage (admins every team, or ?team=),
+// everyone else, or a manager with ?mine=true, their own. ?status= filters
+// (e.g. open), ?limit= caps the list (default 100).
+func HandleGetDisputes(w http.ResponseWriter, r *http.Request) {
+	principal := principalFrom(r)
+	team := r.URL.Query().Get("team")
+	limit := int64(100)
+	if v, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64); err == nil && v > 0 {
+		limit = v
+	}
+
+	var teams []string
+	opener := ""
+	switch {
+	case r.URL.Query().Get("mine") == "true":
+		opener = principal.Email
+	case team != "":
+		if !principal.IsManagerOf(team) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		teams = []string{team}
+	case principal.IsAdmin():
+	case len(principal.ManagedTeams()) > 0:
+		teams = principal.ManagedTeams()
+	default:
+		opener = principal.Email
+	}
+
+	disputes, err := collectionmodels.GetDisputes(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_DISPUTE"), teams, r.URL.Query().Get("status"), opener, limit)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	sla := DisputeSLA()
+	now := time.Now().UTC()
+	views := make([]DisputeView, 0, len(disputes))
+	for _, d := range disputes {
+		views = append(views, newDisputeView(d, sla, now))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(views)
+}
+
+// HandleCommentDispute adds a comment to an open dispute; its opener and the
+// managers of its team can comment. Body: {"id": "...", "text": "..."}.
+func HandleCommentDispute(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ID   string `json:"id"`
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(body.ID)
+	if err != nil {
+		http.Error(w, "Invalid ID format", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(body.Text) == "" {
+		http.Error(w, "text is required", http.StatusBadRequest)
+		return
+	}
+
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	collName := os.Getenv("MONGODB_COLLECTION_DISPUTE")
+	dispute, err := collectionmodels.GetDisputeByID(client, dbName, collName, id)
+	if err == mongo.ErrNoDocuments {
+		http.Error(w, "Dispute not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	principal := principalFrom(r)
+	if !principal.IsManagerOf(dispute.Team) && !strings.EqualFold(dispute.Opener, principal.Email) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	comment := collectionmodels.DisputeComment{Author: principal.Email, Text: body.Text, At: time.Now().UTC()}
+	added, err := collectionmodels.AddDisputeComment(client, dbName, collName, id, comment)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	if !added {
+		http.Error(w, "Dispute is already "+dispute.Status, http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comment)
+}
+
+// HandleResolveDispute accepts or rejects an open dispute of a team the caller
+// manages. An accepted dispute with "patch": true writes the proposed level and
+// tools to the task record. Body: {"id": "...", "action": "accept" | "reject",
+// "comment": "...", "patch": true}.
+func HandleResolveDispute(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ID      string `json:"id"`
+		Action  string `json:"action"`
+		Comment string `json:"comment"`
+		Patch   bool   `json:"patch"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(body.ID)
+	if err != nil {
+		http.Error(w, "Invalid ID format", http.StatusBadRequest)
+		return
+	}
+	var status string
+	switch body.Action {
+	case "accept":
+		status = collectionmodels.DISPUTE_ACCEPTED
+	case "reject":
+		status = collectionmodels.DISPUTE_REJECTED
+	default:
+		http.Error(w, "action must be accept or reject", http.StatusBadRequest)
+		return
+	}
+	patch := body.Patch && status == collectionmodels.DISPUTE_ACCEPTED
+
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	collName := os.Getenv("MONGODB_COLLECTION_DISPUTE")
+	dispute, err := collectionmodels.GetDisputeByID(client, dbName, collName, id)
+	if err == mongo.ErrNoDocuments {
+		http.Error(w, "Dispute not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	principal := principalFrom(r)
+	if !principal.IsManagerOf(dispute.Team) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if !principal.IsAdmin() && strings.EqualFold(dispute.Opener, principal.Email) {
+		http.Error(w, "A dispute cannot be resolved by its opener", http.StatusForbidden)
+		return
+	}
+
+	resolution := collectionmodels.DisputeComment{Author: principal.Email, Text: body.Comment, At: time.Now().UTC()}
+	resolved, err := collectionmodels.ResolveDispute(client, dbName, collName, id, status, resolution)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	if !resolved {
+		http.Error(w, "Dispute is already "+dispute.Status, http.StatusConflict)
+		return
+	}
+
+	if patch {
+		if err := patchDisputedTask(r, dispute, body.Comment); err != nil {
+			failure := collectionmodels.DisputeComment{Author: principal.Email, Text: "patch failed: " + err.Error(), At: time.Now().UTC()}
+			if reopenErr := collectionmodels.ReopenDispute(client, dbName, collName, id, failure); reopenErr != nil {
+				log.Printf("dispute %s: error reopening after failed patch: %v", body.ID, reopenErr)
+			}
+			http.Error(w, "Error patching task: "+err.Error(), http.StatusConflict)
+			return
+		}
+		if err := collectionmodels.MarkDisputePatched(client, dbName, collName, id); err != nil {
+			log.Printf("dispute %s: error marking as patched: %v", body.ID, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"id": body.ID, "status": status, "patched": patch})
+}
+
+// patchDisputedTask writes a dispute's proposed level and tools to its task
+// record, audited, and records the transition. It fails when the record was
+// voided or handed to someone else since the dispute was opened. A later sync
+// from the tracker overwrites the patch unless the task is corrected there too.
+func patchDisputedTask(r *http.Request, dispute *collectionmodels.Dispute, reason string) error {
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	collName := os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK")
+
+	current, err := collectionmodels.GetCompletedTaskByID(client, dbName, collName, dispute.RecordID)
+	if err != nil {
+		return err
+	}
+	if current == nil || current.Void || current.AssigneeID != dispute.AssigneeID {
+		return fmt.Errorf("task %s is no longer a live record", dispute.TaskID)
+	}
+
+	patched := *current
+	if dispute.ProposedLevel != nil {
+		patched.Level = *dispute.ProposedLevel
+	}
+	if len(dispute.ProposedTool) > 0 {
+		patched.Tool = dispute.ProposedTool
+	}
+	err = audited(r, collectionmodels.AUDIT_ENTITY_COMPLETED_TASK, completedTaskKey(current), collName, bson.M{"_id": current.ID}, func() error {
+		return collectionmodels.ReplaceCompletedTask(client, dbName, collName, current.ID, &patched)
+	})
+	if err != nil {
+		return fmt.Errorf("update task %s: %w", dispute.TaskID, err)
+	}
+
+	transition := &collectionmodels.TaskTransition{
+		TaskID:   dispute.TaskID,
+		Action:   collectionmodels.TRANSITION_UPDATED,
+		Reason:   reason,
+		Event:    TRANSITION_EVENT_DISPUTE,
+		EventID:  dispute.ID.Hex(),
+		Before:   current,
+		After:    &patched,
+		Occurred: time.Now().UTC(),
+	}
+	if err := collectionmodels.InsertTaskTransitions(client, dbName, os.Getenv("MONGODB_COLLECTION_TASK_TRANSITION"), []*collectionmodels.TaskTransition{transition}); err != nil {
+		// The record is written; a missing history entry is not worth failing the resolution.
+		log.Printf("dispute %s: error recording transition: %v", dispute.ID.Hex(), err)
+	}
+	return nil
+}
+
+// validateDisputeProposal checks a proposed level against the team's level table
+// and proposed tools against its tools, as in force when the task was done.
+func validateDisputeProposal(rules *scoring.Rules, task *collectionmodels.CompletedTask, level *int, tools []int) error {
+	if level != nil {
+		table := rules.LevelTable(task.Team, task.DoneDate)
+		if table == nil {
+			return fmt.Errorf("team %s has no level table", task.Team)
+		}
+		if *level < 1 || *level > len(table.LevelPoint) {
+			return fmt.Errorf("proposed_level must be between 1 and %d", len(table.LevelPoint))
+		}
+	}
+	seen := map[int]bool{}
+	for _, idx := range tools {
+		if seen[idx] {
+			return fmt.Errorf("tool %d is listed twice", idx)
+		}
+		seen[idx] = true
+		if rules.Tool(task.Team, idx, task.DoneDate) == nil {
+			return fmt.Errorf("team %s has no tool %d", task.Team, idx)
+		}
+	}
+	return nil
+}
+
+func isTaskContributor(task *collectionmodels.CompletedTask, email string) bool {
+	if strings.EqualFold(task.AssigneeID, email) {
+		return true
+	}
+	for _, c := range task.Contributors {
+		if strings.EqualFold(c.AssigneeID, email) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/tasksource"
+)
+
+const TRANSITION_EVENT_IDENTITY = "identity"
+
+// identitiesFromBody reads an optional "Identities": [{"Kind": "clickup", "Value": "123"}];
+// nil when the key is absent.
+func identitiesFromBody(body map[string]interface{}) ([]collectionmodels.Identity, error) {
+	raw, ok := body["Identities"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Identities must be a list")
+	}
+	identities := []collectionmodels.Identity{}
+	for _, item := range list {
+		fields, _ := item.(map[string]interface{})
+		kind, _ := fields["Kind"].(string)
+		value, _ := fields["Value"].(string)
+		identities = append(identities, collectionmodels.Identity{Kind: kind, Value: value})
+	}
+	return identities, nil
+}
+
+// checkIdentities validates member's identities and makes sure none of its
+// accounts already belongs to another member.
+func checkIdentities(member *collectionmodels.Member) error {
+	if err := member.ValidateIdentities(); err != nil {
+		return err
+	}
+	members, err := collectionmodels.GetAllMembers(db.GetMongoClient(), os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"))
+	if err != nil {
+		return err
+	}
+	candidate := *member
+	others := []*collectionmodels.Member{&candidate}
+	for _, m := range members {
+		if m.MemberID != member.MemberID {
+			others = append(others, m)
+		} else if member.Identities == nil {
+			// Identities left out of an update are kept as they are.
+			candidate.Identities = m.Identities
+		}
+	}
+	_, err = db.BuildIdentityIndex(others)
+	return err
+}
+
+// HandleGetUnmatchedAssignees lists the assignees of live completed tasks that
+// are not a member, with their tasks, most tasks first. ?start= and ?end=
+// (RFC3339) bound the done date; the default is the last 90 days.
+func HandleGetUnmatchedAssignees(w http.ResponseWriter, r *http.Request) {
+	endDate := time.Now().UTC()
+	startDate := endDate.AddDate(0, 0, -90)
+	if v := r.URL.Query().Get("start"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "Invalid start", http.StatusBadRequest)
+			return
+		}
+		startDate = t
+	}
+	if v := r.URL.Query().Get("end"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "Invalid end", http.StatusBadRequest)
+			return
+		}
+		endDate = t
+	}
+
+	report, err := db.GetUnmatchedAssignees(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), startDate, endDate)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleRemapAssignee moves the live completed tasks stored under an assignee id
+// that is now a member's identity to that member, recording a transition per
+// record. A record the member already has for the same task is left alone and
+// reported. Body: {"assignee_id": "..."}.
+func HandleRemapAssignee(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		AssigneeID string `json:"assignee_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.AssigneeID == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	index, err := db.LoadIdentityIndex(client, dbName, os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"))
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	member, ok := index.Resolve("", "", body.AssigneeID)
+	if !ok {
+		http.Error(w, "No member has the identity "+body.AssigneeID, http.StatusNotFound)
+		return
+	}
+	if member == body.AssigneeID {
+		http.Error(w, body.AssigneeID+" is already a member's email", http.StatusBadRequest)
+		return
+	}
+
+	collName := os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK")
+	records, err := collectionmodels.GetCompletedTasksByAssignee(client, dbName, collName, body.AssigneeID)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+
+	var transitions []*collectionmodels.TaskTransition
+	moved, skipped := []string{}, []string{}
+	for i := range records {
+		current := &records[i]
+		if current.Void {
+			continue
+		}
+		remapped := *current
+		if remapped.AssigneeID == body.AssigneeID {
+			existing, err := collectionmodels.GetCompletedTask(client, dbName, collName, current.TaskID, member, current.IsConcept())
+			if err != nil {
+				http.Error(w, "Database error: "+err.Error(), 500)
+				return
+			}
+			if existing != nil {
+				skipped = append(skipped, current.TaskID)
+				continue
+			}
+			remapped.AssigneeID = member
+		}
+		ids := make([]string, len(current.Contributors))
+		for j, c := range current.Contributors {
+			ids[j] = c.AssigneeID
+			if c.AssigneeID == body.AssigneeID {
+				ids[j] = member
+			}
+		}
+		remapped.Contributors = tasksource.MergeContributors(current.Contributors, ids)
+		if err := remapped.NormalizeContributors(); err != nil {
+			skipped = append(skipped, current.TaskID)
+			continue
+		}
+		if err := collectionmodels.ReplaceCompletedTask(client, dbName, collName, current.ID, &remapped); err != nil {
+			http.Error(w, "Database error: "+err.Error(), 500)
+			return
+		}
+		moved = append(moved, current.TaskID)
+		transitions = append(transitions, &collectionmodels.TaskTransition{
+			TaskID:   current.TaskID,
+			Action:   collectionmodels.TRANSITION_UPDATED,
+			Reason:   "assignee " + body.AssigneeID + " is " + member,
+			Event:    TRANSITION_EVENT_IDENTITY,
+			EventID:  body.AssigneeID,
+			Before:   current,
+			After:    &remapped,
+			Occurred: time.Now().UTC(),
+		})
+	}
+	if err := collectionmodels.InsertTaskTransitions(client, dbName, os.Getenv("MONGODB_COLLECTION_TASK_TRANSITION"), transitions); err != nil {
+		log.Printf("remap %s: error recording transitions: %v", body.AssigneeID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"assignee_id": body.AssigneeID, "member": member, "moved": moved, "skipped": skipped})
+}
@@ -0,0 +1,129 @@
+package apihandler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"performance-dashboard-backend/internal/clickup"
+	"performance-dashboard-backend/internal/scheduler"
+	"performance-dashboard-backend/internal/syncconfig"
+)
+
+var jobs *scheduler.Scheduler
+
+// InitScheduler registers the background jobs and starts them. Each schedule can
+// be overridden with SCHEDULE_<JOB_NAME> in the environment, "off" disabling it.
+func InitScheduler() {
+	cfg := syncconfig.Get()
+	jobs = scheduler.New(cfg.Location())
+
+	register := func(name, description, spec string, fn scheduler.JobFunc) {
+		if err := jobs.Register(name, description, spec, fn); err != nil {
+			log.Println("Scheduler error:", err)
+		}
+	}
+
+	register("project-report", "Save last week's project report", "0 0 * * 3", func(ctx context.Context) error {
+		return clickup.SaveWeeklyProjectReport(scheduler.TriggerFrom(ctx))
+	})
+	register("session-cleanup", "Remove expired sessions", "@hourly", cleanupExpiredSessions)
+	register("rejection-digest", "Log the open task rejections of each team lead before the weekly cut-off", "0 9 * * 1", logRejectionDigest)
+
+	// Webhooks keep the teams up to date, so the weekly syncs only run when scheduled through the environment.
+	for _, team := range cfg.Teams {
+		register("team-sync-"+strings.ToLower(team.Team), "Sync "+team.Team+" tasks of the current weekly window", scheduler.SPEC_OFF, func(ctx context.Context) error {
+			from, to := cfg.Window(team, time.Now())
+			_, err := clickup.SyncTeam(ctx, team, from, to, scheduler.TriggerFrom(ctx))
+			return err
+		})
+	}
+
+	jobs.Start()
+}
+
+// cleanupExpiredSessions removes expired sessions. MongoDB also expires them
+// through a TTL index; this keeps other stores tidy.
+func cleanupExpiredSessions(ctx context.Context) error {
+	n, err := sessions.DeleteExpired()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("Removed %d expired sessions", n)
+	}
+	return nil
+}
+
+func logRejectionDigest(ctx context.Context) error {
+	report, err := buildRejectionReport(nil)
+	if err != nil {
+		return err
+	}
+	for _, group := range report {
+		lead := group.Lead
+		if lead == "" {
+			lead = "(no manager)"
+		}
+		log.Printf("Rejection digest: %s has %d open rejections in %s", lead, len(group.Rejections), strings.Join(group.Teams, ", "))
+	}
+	return nil
+}
+
+/// ==== Scheduler admin ====
+
+func HandleGetSchedulerJobs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs.Jobs())
+}
+
+// HandleSchedulerJobAction pauses, resumes, reschedules or runs a job.
+// Body: {"name": "project-report", "action": "pause|resume|run|reschedule", "schedule": "0 0 * * 3"}.
+func HandleSchedulerJobAction(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name     string `json:"name"`
+		Action   string `json:"action"`
+		Schedule string `json:"schedule"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch body.Action {
+	case "pause":
+		err = jobs.Pause(body.Name)
+	case "resume":
+		err = jobs.Resume(body.Name)
+	case "run":
+		err = jobs.Trigger(body.Name)
+	case "reschedule":
+		err = jobs.Reschedule(body.Name, body.Schedule)
+	default:
+		http.Error(w, "action must be pause, resume, run or reschedule", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case err == scheduler.ErrUnknownJob:
+		http.Error(w, "Unknown job: "+body.Name, http.StatusNotFound)
+		return
+	case err == scheduler.ErrJobRunning:
+		http.Error(w, "Job is already running", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Scheduler: %s %s by %s", body.Action, body.Name, principalFrom(r).Email)
+
+	status, _ := jobs.Job(body.Name)
+	w.Header().Set("Content-Type", "application/json")
+	if body.Action == "run" {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(status)
+}
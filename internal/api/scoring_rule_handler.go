@@ -0,0 +1,116 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"time"
+
+	db "performance-dashboard-backend/internal/database"
+)
+
+// HandlePreviewRuleChange shows how a proposed level table / tool points change
+// would alter a team's past weekly totals, without saving the change.
+// Body: {"team", "effective_from", "level_point": [...], "tools": [{"tool_name", "type", "point", "index"}],
+// "start_date", "end_date"}; effective_from defaults to start_date.
+func HandlePreviewRuleChange(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		db.RuleChange
+		StartDate time.Time `json:"start_date"`
+		EndDate   time.Time `json:"end_date"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if body.Team == "" || body.StartDate.IsZero() || body.EndDate.IsZero() {
+		http.Error(w, "team, start_date and end_date are required", http.StatusBadRequest)
+		return
+	}
+	if len(body.LevelPoint) == 0 && len(body.Tools) == 0 {
+		http.Error(w, "level_point or tools is required", http.StatusBadRequest)
+		return
+	}
+	if body.EffectiveFrom.IsZero() {
+		body.EffectiveFrom = body.StartDate
+	}
+
+	weeks, err := db.PreviewRuleChange(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), &body.RuleChange, body.StartDate, body.EndDate)
+	if err != nil {
+		http.Error(w, "Preview error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(weeks)
+}
+
+// effectiveFromBody reads the optional "EffectiveFrom" (RFC3339) of a level or
+// tool update; nil means the change applies from now on.
+func effectiveFromBody(body map[string]interface{}) (*time.Time, error) {
+	return timeFromBody(body, "EffectiveFrom")
+}
+
+// timeFromBody reads an optional RFC3339 time; nil when key is absent or empty.
+func timeFromBody(body map[string]interface{}, key string) (*time.Time, error) {
+	raw, ok := body[key].(string)
+	if !ok || raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// HandleSimulateScoring scores real completed tasks with candidate level / tool
+// tables next to the stored rules, per member and week. Nothing is saved.
+// Body: {"levels": [{"team", "level_point"}], "tools": [{"team", "tool_name", "type", "point", "index"}],
+// "teams": [...], "members": [...], "start_date", "end_date"}. Managers can only
+// simulate their own teams and their members.
+func HandleSimulateScoring(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		db.CandidateRules
+		Teams     []string  `json:"teams"`
+		Members   []string  `json:"members"`
+		StartDate time.Time `json:"start_date"`
+		EndDate   time.Time `json:"end_date"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if body.StartDate.IsZero() || body.EndDate.IsZero() || body.EndDate.Before(body.StartDate) {
+		http.Error(w, "start_date and end_date are required, end_date not before start_date", http.StatusBadRequest)
+		return
+	}
+	if len(body.Teams) == 0 && len(body.Members) == 0 {
+		http.Error(w, "teams or members is required", http.StatusBadRequest)
+		return
+	}
+
+	principal := principalFrom(r)
+	if !principal.IsAdmin() {
+		for _, team := range body.Teams {
+			if !principal.IsManagerOf(team) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+		}
+		for _, email := range body.Members {
+			member, err := db.GetMemberByEmail(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), email)
+			if err != nil || member == nil || !principal.IsManagerOf(member.Team) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+		}
+	}
+
+	result, err := db.SimulateScoring(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), &body.CandidateRules, body.Teams, body.Members, body.StartDate, body.EndDate)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
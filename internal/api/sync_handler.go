@@ -0,0 +1,181 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"performance-dashboard-backend/internal/clickup"
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// HandleBackfill imports a team's tasks for a past date range, week by week.
+// Body: {"team": "Art", "from": RFC3339, "to": RFC3339, "dry_run": false}.
+func HandleBackfill(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Team   string    `json:"team"`
+		From   time.Time `json:"from"`
+		To     time.Time `json:"to"`
+		DryRun bool      `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if body.Team == "" || body.From.IsZero() || body.To.IsZero() {
+		http.Error(w, "team, from and to are required", http.StatusBadRequest)
+		return
+	}
+
+	report, err := clickup.Backfill(r.Context(), body.Team, body.From, body.To, body.DryRun, collectionmodels.SYNC_TRIGGER_MANUAL, func(week *clickup.BackfillWeek) {
+		log.Printf("backfill %s: week %s: fetched=%d inserted=%d existing=%d %s", body.Team, week.From.Format("2006-01-02"), week.Fetched, week.Inserted, week.Existing, week.Error)
+	})
+	if err != nil && report == nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("backfill %s stopped early: %v", body.Team, err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleSyncDryRun shows what syncing a team would change, without writing.
+// Body: {"team": "Art", "from": RFC3339, "to": RFC3339}; from/to default to the
+// team's current weekly window.
+func HandleSyncDryRun(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Team string    `json:"team"`
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	cfg := syncconfig.Get()
+	team := cfg.Team(body.Team)
+	if team == nil {
+		http.Error(w, "unknown team: "+body.Team, http.StatusBadRequest)
+		return
+	}
+	if body.From.IsZero() || body.To.IsZero() {
+		body.From, body.To = cfg.Window(team, time.Now())
+	}
+
+	diff, err := clickup.DryRunTeam(r.Context(), team, body.From, body.To)
+	if err != nil {
+		http.Error(w, "Sync error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// HandleWebhookDryRun shows the record changes a webhook event for the task would
+// make right now. Body: {"task_id": "...", "kind": "task" | "concept"}.
+func HandleWebhookDryRun(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		TaskID string `json:"task_id"`
+		Kind   string `json:"kind"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if body.TaskID == "" {
+		http.Error(w, "missing task_id", http.StatusBadRequest)
+		return
+	}
+	if body.Kind != WEBHOOK_KIND_CONCEPT {
+		body.Kind = WEBHOOK_KIND_TASK
+	}
+
+	event := &collectionmodels.WebhookEvent{EventID: "dry-run", Event: clickup.EVENT_TASK_UPDATED, TaskID: body.TaskID, Kind: body.Kind}
+	transitions, err := planClickUpWebhook(event)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"task_id":     body.TaskID,
+		"transitions": transitions,
+	})
+}
+
+// HandleSyncTeam runs a team sync now. Body: {"team": "Art", "from": RFC3339, "to": RFC3339};
+// from/to default to the team's current weekly window.
+func HandleSyncTeam(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Team string    `json:"team"`
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	cfg := syncconfig.Get()
+	team := cfg.Team(body.Team)
+	if team == nil {
+		http.Error(w, "unknown team: "+body.Team, http.StatusBadRequest)
+		return
+	}
+	if body.From.IsZero() || body.To.IsZero() {
+		body.From, body.To = cfg.Window(team, time.Now())
+	}
+
+	run, err := clickup.SyncTeam(r.Context(), team, body.From, body.To, collectionmodels.SYNC_TRIGGER_MANUAL)
+	if err != nil {
+		log.Printf("sync %s failed: %v", team.Team, err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+/// ==== Sync runs ====
+
+// HandleGetSyncRuns lists recent sync runs; ?kind=, ?team= and ?limit= narrow the list.
+func HandleGetSyncRuns(w http.ResponseWriter, r *http.Request) {
+	limit := int64(50)
+	if v, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64); err == nil && v > 0 {
+		limit = v
+	}
+	runs, err := collectionmodels.GetSyncRuns(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_SYNC_RUN"), r.URL.Query().Get("kind"), r.URL.Query().Get("team"), limit)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
+
+// HandleGetSyncRun returns one run with its rejected tasks and errors: ?id=.
+func HandleGetSyncRun(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	run, err := collectionmodels.GetSyncRunByID(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_SYNC_RUN"), id)
+	if err == mongo.ErrNoDocuments {
+		http.Error(w, "Sync run not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
@@ -0,0 +1,97 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/scoring"
+)
+
+// TaskPointExplanation is how the points of one stored record of a task were derived.
+type TaskPointExplanation struct {
+	Task       collectionmodels.CompletedTask `json:"task"`
+	Identifier string                         `json:"identifier"`
+	Score      scoring.Score                  `json:"score"`
+}
+
+// HandleExplainTaskPoints returns, for ?id=<task id>, the derivation of the points
+// of every live record of the task: level table and entry, matched and unmatched
+// tools, the factor chain and each point. With ?assignee= only the records that
+// credit that assignee are returned, with the points of their share; otherwise
+// the whole task. Members of the task's team, its contributors and admins can see it.
+func HandleExplainTaskPoints(w http.ResponseWriter, r *http.Request) {
+	taskID := r.URL.Query().Get("id")
+	if taskID == "" {
+		http.Error(w, "Missing id", http.StatusBadRequest)
+		return
+	}
+	assignee := r.URL.Query().Get("assignee")
+
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	records, err := collectionmodels.GetLiveCompletedTasks(client, dbName, os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), taskID)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	if len(records) == 0 {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+
+	principal := principalFrom(r)
+	for i := range records {
+		if !canViewTaskPoints(principal, &records[i]) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+	}
+
+	rules, err := scoring.LoadRules(client, dbName)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	explanations := make([]TaskPointExplanation, 0, len(records))
+	for i := range records {
+		identifier := records[i].Team
+		if assignee != "" {
+			if identifier = creditedID(&records[i], assignee); identifier == "" {
+				continue
+			}
+		}
+		explanations = append(explanations, TaskPointExplanation{
+			Task:       records[i],
+			Identifier: identifier,
+			Score:      rules.ScoreTask(&records[i], identifier),
+		})
+	}
+	if len(explanations) == 0 {
+		http.Error(w, "Assignee has no record of this task", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(explanations)
+}
+
+// creditedID is the id under which task credits email, as the assignee or a
+// contributor, or "" when it does not.
+func creditedID(task *collectionmodels.CompletedTask, email string) string {
+	for _, c := range task.Contributors {
+		if strings.EqualFold(c.AssigneeID, email) {
+			return c.AssigneeID
+		}
+	}
+	if strings.EqualFold(task.AssigneeID, email) {
+		return task.AssigneeID
+	}
+	return ""
+}
+
+func canViewTaskPoints(p *Principal, task *collectionmodels.CompletedTask) bool {
+	return p.IsAdmin() || contains(p.Teams(), task.Team) || isTaskContributor(task, p.Email)
+}
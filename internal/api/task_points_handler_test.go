@@ -0,0 +1,42 @@
+package apihandler
+
+import (
+	"testing"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+)
+
+func TestCreditedID(t *testing.T) {
+	solo := &collectionmodels.CompletedTask{AssigneeID: "a@example.com"}
+	shared := &collectionmodels.CompletedTask{
+		AssigneeID: "a@example.com",
+		Contributors: []collectionmodels.Contributor{
+			{AssigneeID: "a@example.com", Share: 0.7},
+			{AssigneeID: "B@example.com", Share: 0.3},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		task  *collectionmodels.CompletedTask
+		email string
+		want  string
+		share float64
+	}{
+		{"sole assignee", solo, "a@example.com", "a@example.com", 1},
+		{"not on a solo task", solo, "b@example.com", "", 0},
+		{"primary contributor", shared, "a@example.com", "a@example.com", 0.7},
+		{"contributor in another case", shared, "b@example.com", "B@example.com", 0.3},
+		{"not a contributor", shared, "c@example.com", "", 0},
+	}
+	for _, tt := range tests {
+		got := creditedID(tt.task, tt.email)
+		if got != tt.want {
+			t.Errorf("%s: credited as %q, want %q", tt.name, got, tt.want)
+			continue
+		}
+		if got != "" && tt.task.CreditShare(got) != tt.share {
+			t.Errorf("%s: share %v, want %v", tt.name, tt.task.CreditShare(got), tt.share)
+		}
+	}
+}
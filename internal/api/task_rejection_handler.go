@@ -0,0 +1,98 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+)
+
+// RejectionReportGroup is the open rejections of the teams one lead manages.
+// Rejections of a team without a manager are grouped under an empty lead.
+type RejectionReportGroup struct {
+	Lead       string                          `json:"lead"`
+	LeadName   string                          `json:"lead_name,omitempty"`
+	Teams      []string                        `json:"teams"`
+	Rejections []collectionmodels.RejectedTask `json:"rejections"`
+}
+
+// HandleTaskRejectionReport lists the ClickUp tasks that are not earning points
+// because of missing or invalid fields, grouped by team lead. Admins see every
+// team (or ?team=), managers the teams they manage.
+func HandleTaskRejectionReport(w http.ResponseWriter, r *http.Request) {
+	principal := principalFrom(r)
+	team := r.URL.Query().Get("team")
+
+	var teams []string
+	if !principal.IsAdmin() {
+		teams = principal.ManagedTeams()
+	}
+	if team != "" {
+		if !principal.IsManagerOf(team) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		teams = []string{team}
+	}
+
+	report, err := buildRejectionReport(teams)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// buildRejectionReport groups the open rejections of the given teams (all teams
+// when empty) by the managers of each team.
+func buildRejectionReport(teams []string) ([]*RejectionReportGroup, error) {
+	rejections, err := collectionmodels.GetOpenRejectedTasks(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TASK_REJECTION"), teams)
+	if err != nil {
+		return nil, err
+	}
+	members, err := db.GetMembersByTeam(os.Getenv("MONGO_URI"), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"), "")
+	if err != nil {
+		return nil, err
+	}
+
+	leadsByTeam := map[string][]*collectionmodels.Member{}
+	for _, m := range members {
+		if strings.EqualFold(m.Role, "manager") {
+			leadsByTeam[m.Team] = append(leadsByTeam[m.Team], m)
+		}
+	}
+
+	groups := map[string]*RejectionReportGroup{}
+	add := func(email, name string, rejection collectionmodels.RejectedTask) {
+		g, ok := groups[email]
+		if !ok {
+			g = &RejectionReportGroup{Lead: email, LeadName: name, Teams: []string{}, Rejections: []collectionmodels.RejectedTask{}}
+			groups[email] = g
+		}
+		if !contains(g.Teams, rejection.Team) {
+			g.Teams = append(g.Teams, rejection.Team)
+		}
+		g.Rejections = append(g.Rejections, rejection)
+	}
+	for _, rejection := range rejections {
+		leads := leadsByTeam[rejection.Team]
+		if len(leads) == 0 {
+			add("", "", rejection)
+		}
+		for _, lead := range leads {
+			add(lead.Email, lead.Name, rejection)
+		}
+	}
+
+	report := make([]*RejectionReportGroup, 0, len(groups))
+	for _, g := range groups {
+		report = append(report, g)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Lead < report[j].Lead })
+	return report, nil
+}
@@ -0,0 +1,103 @@
+package apihandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	db "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/tasksource"
+)
+
+const TRANSITION_EVENT_SOURCE_CONFLICT = "source_conflict"
+
+// HandleGetTaskSources lists the registered task sources.
+func HandleGetTaskSources(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tasksource.Names())
+}
+
+// HandleGetSourceConflicts lists tasks with live records from more than one source.
+func HandleGetSourceConflicts(w http.ResponseWriter, r *http.Request) {
+	limit := int64(100)
+	if v, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64); err == nil && v > 0 {
+		limit = v
+	}
+	conflicts, err := collectionmodels.GetSourceConflicts(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), limit)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conflicts)
+}
+
+// HandleResolveSourceConflict keeps the records of one source for a task and voids
+// the others as superseded. Body: {"task_id": "...", "keep": "manual"}.
+func HandleResolveSourceConflict(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		TaskID string `json:"task_id"`
+		Keep   string `json:"keep"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if body.TaskID == "" || body.Keep == "" {
+		http.Error(w, "task_id and keep are required", http.StatusBadRequest)
+		return
+	}
+
+	client := db.GetMongoClient()
+	dbName := os.Getenv("MONGODB_NAME")
+	collName := os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK")
+	records, err := collectionmodels.GetLiveCompletedTasks(client, dbName, collName, body.TaskID)
+	if err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+	kept := 0
+	for _, record := range records {
+		if record.RecordSource() == body.Keep {
+			kept++
+		}
+	}
+	if kept == 0 {
+		http.Error(w, "Task has no live record from source "+body.Keep, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	var transitions []*collectionmodels.TaskTransition
+	for i := range records {
+		record := records[i]
+		if record.RecordSource() == body.Keep {
+			continue
+		}
+		if err := collectionmodels.VoidCompletedTask(client, dbName, collName, record.ID, collectionmodels.VOID_REASON_SUPERSEDED, now); err != nil {
+			http.Error(w, "Database error: "+err.Error(), 500)
+			return
+		}
+		after := record
+		after.Void, after.VoidReason, after.VoidedAt = true, collectionmodels.VOID_REASON_SUPERSEDED, &now
+		transitions = append(transitions, &collectionmodels.TaskTransition{
+			TaskID:   body.TaskID,
+			Action:   collectionmodels.TRANSITION_VOIDED,
+			Reason:   collectionmodels.VOID_REASON_SUPERSEDED,
+			Event:    TRANSITION_EVENT_SOURCE_CONFLICT,
+			Before:   &record,
+			After:    &after,
+			Occurred: now,
+		})
+	}
+	if err := collectionmodels.InsertTaskTransitions(client, dbName, os.Getenv("MONGODB_COLLECTION_TASK_TRANSITION"), transitions); err != nil {
+		http.Error(w, "Database error: "+err.Error(), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"task_id": body.TaskID, "kept": kept, "voided": len(transitions)})
+}
@@ -2,276 +2,235 @@ package asana
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/robfig/cron/v3"
-	"go.mongodb.org/mongo-driver/mongo"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+	"performance-dashboard-backend/internal/tasksource"
 )
 
-func FetchTaskList(token string, spaceID string) ([]ClickUpTaskListResponse, error) {
-	// Build ClickUp API URL
-	url := fmt.Sprintf("https://api.clickup.com/api/v2/space/%s/list", spaceID)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
+// FetchCompletedTasks fetches the tasks of an asana source completed in [from, to)
+// and converts them with the team's field rules. Subtasks are included when the
+// team counts them.
+func (c *Client) FetchCompletedTasks(ctx context.Context, team *syncconfig.Team, source *syncconfig.Source, from, to time.Time) (*tasksource.Result, error) {
+	var tasks []Task
+	var err error
+	if source.Section != "" {
+		tasks, err = c.FetchSectionTasks(ctx, source.Section, from)
+	} else {
+		tasks, err = c.FetchProjectTasks(ctx, source.Project, from)
 	}
-	req.Header.Set("Authorization", token)
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	// Read body
-	body, _ := io.ReadAll(resp.Body)
-
-	// Debug: Print response status and body
-	fmt.Printf("ClickUp API Response Status: %d\n", resp.StatusCode)
-
-	// Parse JSON - the response has a "lists" wrapper
-	var listsResp ClickUpListsResponse
-	if err := json.Unmarshal(body, &listsResp); err != nil {
-		fmt.Printf("Error unmarshalling ClickUp response: %v\nResponse body: %s\n", err, string(body))
-		return nil, err
+		return nil, fmt.Errorf("fetch %s tasks from asana project %s: %w", team.Team, source.Project, err)
 	}
-
-	return listsResp.Lists, nil
-}
-
-func FetchTasksFromAllLists(token string, spaceID string) ([]Task, error) {
-	taskLists, err := FetchTaskList(token, spaceID)
-	if err != nil {
-		return nil, err
-	}
-	var allTasks []Task
-	for _, list := range taskLists {
-		tasks, err := FetchTasks(token, list.Id)
-		if err != nil {
-			return nil, err
+	if team.IncludeSubtasks {
+		for i := range tasks {
+			if tasks[i].NumSubtasks == 0 {
+				continue
+			}
+			subtasks, err := c.FetchSubtasks(ctx, tasks[i].GID)
+			if err != nil {
+				return nil, fmt.Errorf("fetch subtasks of asana task %s: %w", tasks[i].GID, err)
+			}
+			tasks = append(tasks, subtasks...)
 		}
-		allTasks = append(allTasks, tasks...)
 	}
-	return allTasks, nil
-}
-
-func FetchTasks(token string, listID string) ([]Task, error) {
-	var allTasks []Task
-	page := 0
 
-	for {
-		// Build ClickUp API URL with pagination
-		url := fmt.Sprintf("https://api.clickup.com/api/v2/list/%s/task?page=%d", listID, page)
+	cfg := syncconfig.Get()
+	result := &tasksource.Result{}
+	var accepted []*collectionmodels.CompletedTask
+	for i := range tasks {
+		task := &tasks[i]
+		// completed_since also returns every incomplete task; only completed, tagged ones were "fetched".
+		if !task.Completed || !hasTag(task, source.Tag) {
+			continue
+		}
+		result.Fetched++
 
-		// Build request
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
+		doneAt, ok := doneTime(task, source, cfg.Location())
+		if !ok || doneAt.Before(from) || !doneAt.Before(to) {
+			result.Skipped++
+			continue
 		}
-		req.Header.Set("Authorization", token)
-		req.Header.Set("Content-Type", "application/json")
 
-		// Send request
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		completedTask, err := normalizeTask(task, team, source, doneAt)
 		if err != nil {
-			return nil, err
+			fmt.Println("Rejected asana task:", err)
+			result.Rejections = append(result.Rejections, err.Rejection)
+			continue
 		}
-		defer resp.Body.Close()
-
-		// Read body
-		body, _ := io.ReadAll(resp.Body)
-
-		// Debug: Print response status and body
-		fmt.Printf("ClickUp API Response Status: %d\n", resp.StatusCode)
-		fmt.Printf("ClickUp API Response Body: %s\n", string(body))
+		accepted = append(accepted, completedTask)
+	}
+	if source.DedupeByName {
+		deduped := collectionmodels.DedupeCompletedTasksByName(accepted)
+		result.Skipped += len(accepted) - len(deduped)
+		accepted = deduped
+	}
+	result.Tasks = accepted
+	return result, nil
+}
 
-		// Parse JSON
-		var clickupResp ClickUpResponse
-		if err := json.Unmarshal(body, &clickupResp); err != nil {
-			fmt.Printf("Error unmarshalling ClickUp response: %v\nResponse body: %s\n", err, string(body))
-			return nil, err
+// BuildCompletedTask reads the scored fields of an Asana task with the same rules
+// as ClickUp: a numeric difficulty, tool options named "<index> <tool>" and a
+// project option named "<code> <game>". DoneDate is left for the caller.
+func BuildCompletedTask(task *Task, team *syncconfig.Team, source *syncconfig.Source) (*collectionmodels.CompletedTask, *collectionmodels.TaskRejection) {
+	assigneeEmail := ""
+	if task.Assignee != nil {
+		assigneeEmail = task.Assignee.Email
+	}
+	reject := func(code, reason string) *collectionmodels.TaskRejection {
+		return &collectionmodels.TaskRejection{
+			TaskID:     task.GID,
+			TaskName:   task.Name,
+			URL:        task.PermalinkURL,
+			Team:       team.Team,
+			AssigneeID: assigneeEmail,
+			Code:       code,
+			Reason:     reason,
 		}
+	}
 
-		// Collect tasks
-		allTasks = append(allTasks, clickupResp.Tasks...)
+	fields := make(map[string]*CustomField, len(task.CustomFields))
+	for i := range task.CustomFields {
+		fields[task.CustomFields[i].Name] = &task.CustomFields[i]
+	}
 
-		// Check if last page
-		if clickupResp.LastPage || len(clickupResp.Tasks) == 0 {
-			break
+	toolIndexes := []int{}
+	if toolField, ok := fields[team.ToolField]; ok {
+		for _, option := range toolField.MultiEnumValues {
+			if inx := toolIndex(option.Name); inx != -1 {
+				toolIndexes = append(toolIndexes, inx)
+			}
 		}
-		page++
 	}
 
-	return allTasks, nil
-}
-
-func InsertCompletedTaskToDataBase(client *mongo.Client, dbName, collName string, task *collectionmodels.CompletedTask) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	collection := client.Database(dbName).Collection(collName)
-	_, err := collection.InsertOne(ctx, task)
-	return err
-}
+	difficultField, ok := fields[team.DifficultyField]
+	if !ok || difficultField.isEmpty() {
+		return nil, reject(collectionmodels.REJECT_MISSING_DIFFICULTY, fmt.Sprintf("%q field missing", team.DifficultyField))
+	}
+	level, ok := difficultField.intValue()
+	if !ok {
+		return nil, reject(collectionmodels.REJECT_INVALID_DIFFICULTY, fmt.Sprintf("invalid %q value %s", team.DifficultyField, difficultField.display()))
+	}
 
-func FetchAsanaTasksByTeam(team string, spaceID string) []*collectionmodels.CompletedTask {
-	token := os.Getenv("CLICKUP_TOKEN") // safer to set as env var
+	projectField, ok := fields[team.ProjectField]
+	if !ok || projectField.isEmpty() {
+		return nil, reject(collectionmodels.REJECT_MISSING_PROJECT, fmt.Sprintf("%q field missing", team.ProjectField))
+	}
+	projectName := projectField.display()
+	if spaceIdx := strings.Index(projectName, " "); spaceIdx != -1 {
+		projectName = projectName[spaceIdx+1:]
+	}
+	if projectName == "" {
+		return nil, reject(collectionmodels.REJECT_INVALID_PROJECT, fmt.Sprintf("invalid %q value", team.ProjectField))
+	}
 
-	tasks, err := FetchTasksFromAllLists(token, spaceID)
-	if err != nil {
-		fmt.Println("Error:", err)
-		return nil
+	if assigneeEmail == "" {
+		return nil, reject(collectionmodels.REJECT_NO_ASSIGNEE, "no assignee")
 	}
 
-	fmt.Println("all task", len(tasks), "tasks fetched from ClickUp space", tasks)
+	return &collectionmodels.CompletedTask{
+		TaskID:     task.GID,
+		TaskName:   task.Name,
+		AssigneeID: tasksource.ResolveAssignee(collectionmodels.SOURCE_ASANA, task.Assignee.GID, assigneeEmail),
+		Tool:       toolIndexes,
+		Level:      level,
+		Project:    projectName,
+		Team:       team.Team,
+		TaskType:   source.TaskType,
+		Source:     collectionmodels.SOURCE_ASANA,
+	}, nil
+}
 
-	var completedTasks []*collectionmodels.CompletedTask
-	loc, _ := time.LoadLocation("Asia/Ho_Chi_Minh")
-	now := time.Now().In(loc)
-	weekday := int(now.Weekday())
-	if weekday == 0 {
-		weekday = 7
+func hasTag(task *Task, tag string) bool {
+	if tag == "" {
+		return true
 	}
-	// This Monday at 09:00 Vietnam time
-	thisMondayAtNine := time.Date(
-		now.Year(),
-		now.Month(),
-		now.Day()-weekday+1,
-		9, 0, 0, 0,
-		loc,
-	)
-	count := 0
-	rejectedCount := 0
-	for _, task := range tasks {
-		// Check if task is completed (closed status)
-		if task.Status == nil || task.Status.Type != "closed" {
-			continue
+	for _, t := range task.Tags {
+		if strings.EqualFold(strings.TrimSpace(t.Name), tag) {
+			return true
 		}
-		count++
-
-		// Map ClickUp task to internal model
-		var toolIndexes []int
-		var level int
-		var projectName string
-		for _, field := range task.CustomFields {
-			valueStr := fmt.Sprintf("%v", field.Value)
+	}
+	return false
+}
 
-			if field.Name == "Tool/CTST PLA" || field.Name == "Tool/CTST Video" || field.Name == "Tool/CTST Art" || field.Name == "Tool/CTST Concept" {
-				toolIndexes = GetListToolAsIndexes(valueStr)
-			}
-			if field.Name == "PLA Difficult" || field.Name == "Art point" || field.Name == "Video Difficult" || field.Name == "Concept Difficult" {
-				// Try parsing as integer first, fall back to float
-				if lvl, err := strconv.Atoi(valueStr); err == nil {
-					level = lvl
-				} else if lvl, err := strconv.ParseFloat(valueStr, 64); err == nil {
-					level = int(lvl)
-				}
-			}
-			if field.Name == "Game Name" {
-				projectName = valueStr
-			}
+// doneTime is when the task was completed, or the date custom field configured as
+// the source's done date.
+func doneTime(task *Task, source *syncconfig.Source, loc *time.Location) (time.Time, bool) {
+	if source.DoneDateFieldID == "" {
+		if task.CompletedAt == nil {
+			return time.Time{}, false
 		}
-		// fmt.Println("Processing completed task:", task.Name, "Level:", level, "Tools:", toolIndexes)
-		if level < 1 {
-			rejectedCount++
+		return *task.CompletedAt, true
+	}
+	for _, cf := range task.CustomFields {
+		if cf.GID != source.DoneDateFieldID || cf.DateValue == nil {
 			continue
 		}
-
-		if toolIndexes == nil {
-			toolIndexes = []int{}
+		if cf.DateValue.DateTime != nil {
+			return *cf.DateValue.DateTime, true
 		}
-
-		// Get assignee email (use first assignee if multiple)
-		assigneeEmail := ""
-		if len(task.Assignees) > 0 {
-			assigneeEmail = task.Assignees[0].Email
+		if d, err := time.ParseInLocation("2006-01-02", cf.DateValue.Date, loc); err == nil {
+			return d, true
 		}
-
-		completedTask := &collectionmodels.CompletedTask{
-			TaskID:     task.Id,
-			DoneDate:   thisMondayAtNine,
-			TaskName:   task.Name,
-			AssigneeID: assigneeEmail,
-			Team:       team,
-			Tool:       toolIndexes,
-			Project:    projectName,
-			Level:      level,
-		}
-		// frint all the fields
-		// fmt.Printf("TaskID: %s, TaskName: %s, AssigneeID: %s, Team: %s, Tool: %v, Level: %d, Project: %s, DoneDate: %s\n",
-		// 	completedTask.TaskID, completedTask.TaskName, completedTask.AssigneeID, completedTask.Team,
-		// 	completedTask.Tool, completedTask.Level, completedTask.Project, completedTask.DoneDate.Format("2006-01-02"))
-
-		//fmt.Print("ID ", completedTask.TaskID, " | Name: ", completedTask.TaskName, " | Assignee: ", completedTask.AssigneeID, " | Tool: ", completedTask.Tool, " | Level: ", completedTask.Level, " | Project: ", completedTask.Project, " | DoneDate: ", completedTask.DoneDate.Format("2006-01-02"), "\n")
-		completedTasks = append(completedTasks, completedTask)
 	}
-	return completedTasks
+	return time.Time{}, false
 }
 
-func GetListToolAsIndexes(s string) []int {
-	re := regexp.MustCompile(`\d+`)
-	matches := re.FindAllString(s, -1)
+// normalizeTask builds the record of a task done at doneAt and files it under its work week.
+func normalizeTask(task *Task, team *syncconfig.Team, source *syncconfig.Source, doneAt time.Time) (*collectionmodels.CompletedTask, *tasksource.RejectionError) {
+	week := syncconfig.Get().DoneWeek(team, doneAt)
+	completedTask, rejection := BuildCompletedTask(task, team, source)
+	if rejection != nil {
+		rejection.Week = week
+		return nil, &tasksource.RejectionError{Rejection: *rejection}
+	}
+	completedTask.DoneDate = week
+	return completedTask, nil
+}
 
-	var numbers []int
-	for _, m := range matches {
-		n, err := strconv.Atoi(m)
-		if err == nil {
-			numbers = append(numbers, n)
-		}
+// toolIndex is the leading number of a tool option name, or -1 (same rule as ClickUp).
+func toolIndex(name string) int {
+	match := regexp.MustCompile(`^\d+`).FindString(strings.TrimSpace(name))
+	if match == "" {
+		return -1
 	}
-	return numbers
+	num, _ := strconv.Atoi(match)
+	return num
 }
 
-// schedule to run every Monday at 11:59 AM
-// Implementation of scheduling logic goes here
-func ScheduleWeeklyTaskSync() {
-	loc, err := time.LoadLocation("Asia/Ho_Chi_Minh")
-	if err != nil {
-		fmt.Println("Cannot load Asia/Ho_Chi_Minh, fallback UTC:", err)
-		loc = time.UTC
+func (cf *CustomField) isEmpty() bool {
+	return cf.NumberValue == nil && cf.EnumValue == nil && (cf.TextValue == nil || *cf.TextValue == "") &&
+		(cf.DisplayValue == nil || *cf.DisplayValue == "")
+}
+
+// intValue reads a number field, or the leading number of an enum / text value.
+func (cf *CustomField) intValue() (int, bool) {
+	if cf.NumberValue != nil {
+		return int(*cf.NumberValue), true
 	}
-	c := cron.New(cron.WithLocation(loc))
-	_, err = c.AddFunc("59 23 * * 1", SyncronizeWeeklyTasks)
-	if err != nil {
-		fmt.Println("Cron add error:", err)
-		return
+	match := regexp.MustCompile(`^\d+`).FindString(strings.TrimSpace(cf.display()))
+	if match == "" {
+		return 0, false
 	}
-	c.Start()
+	n, err := strconv.Atoi(match)
+	return n, err == nil
 }
 
-func SyncronizeWeeklyTasks() {
-	fmt.Println("Starting weekly ClickUp task synchronization...")
-	plaCompltedTasks := FetchAsanaTasksByTeam("PLA", os.Getenv("CLICKUP_SPACE_ID_CONCEPT"))
-	fmt.Printf("Inserting %d PLA completed tasks into the database...\n", len(plaCompltedTasks))
-	// if len(plaCompltedTasks) > 0 {
-	// 	collectionmodels.InsertCompletedTaskToDataBase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), plaCompltedTasks)
-	// }
-	// videoCompletedTasks := FetchAsanaTasksByTeam("Video", os.Getenv("CLICKUP_SPACE_ID_VIDEO"))
-	// fmt.Printf("Inserting %d Video completed tasks into the database...\n", len(videoCompletedTasks))
-	// if len(videoCompletedTasks) > 0 {
-	// 	collectionmodels.InsertCompletedTaskToDataBase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), videoCompletedTasks)
-	// }
-	// artCompletedTasks := FetchAsanaTasksByTeam("Art", os.Getenv("CLICKUP_SPACE_ID_ART"))
-	// fmt.Printf("Inserting %d Art completed tasks into the database...\n", len(artCompletedTasks))
-	// if len(artCompletedTasks) > 0 {
-	// 	collectionmodels.InsertCompletedTaskToDataBase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), artCompletedTasks)
-	// }
-	// conceptCompletedTasks := FetchAsanaTasksByTeam("Concept", os.Getenv("CLICKUP_SPACE_ID_CONCEPT"))
-	// fmt.Printf("Inserting %d Concept completed tasks into the database...\n", len(conceptCompletedTasks))
-	// if len(conceptCompletedTasks) > 0 {
-	// 	collectionmodels.InsertCompletedTaskToDataBase(db.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), conceptCompletedTasks)
-	// }
-	// fmt.Println("Weekly ClickUp task synchronization completed.")
+func (cf *CustomField) display() string {
+	switch {
+	case cf.EnumValue != nil:
+		return cf.EnumValue.Name
+	case cf.TextValue != nil:
+		return *cf.TextValue
+	case cf.DisplayValue != nil:
+		return *cf.DisplayValue
+	case cf.NumberValue != nil:
+		return strconv.FormatFloat(*cf.NumberValue, 'f', -1, 64)
+	}
+	return ""
 }
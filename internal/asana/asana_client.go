@@ -0,0 +1,261 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBaseURL    = "https://app.asana.com/api/1.0"
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 5
+	defaultMaxBackoff = time.Minute
+	pageLimit         = 100
+)
+
+// taskFields is the opt_fields list every task request asks for.
+var taskFields = strings.Join([]string{
+	"name", "completed", "completed_at", "permalink_url", "num_subtasks",
+	"assignee.name", "assignee.email",
+	"tags.name",
+	"memberships.project.name", "memberships.section.name",
+	"custom_fields.name", "custom_fields.type", "custom_fields.number_value",
+	"custom_fields.text_value", "custom_fields.display_value", "custom_fields.date_value",
+	"custom_fields.enum_value.name", "custom_fields.multi_enum_values.name",
+}, ",")
+
+// Client talks to the Asana REST API with a personal access token. It retries
+// 429 responses after Retry-After and 5xx responses / network errors with
+// exponential backoff. BaseURL can point at a local fake server.
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+	MaxRetries int
+	MaxBackoff time.Duration
+}
+
+// APIError is returned for a non-2xx response that was not (or no longer) retried.
+type APIError struct {
+	StatusCode int
+	Path       string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("asana request %s failed (status=%d): %s", e.Path, e.StatusCode, e.Body)
+}
+
+func NewClient(token string) *Client {
+	return &Client{
+		BaseURL:    defaultBaseURL,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: defaultTimeout},
+		MaxRetries: defaultMaxRetries,
+		MaxBackoff: defaultMaxBackoff,
+	}
+}
+
+// NewClientFromEnv reads ASANA_TOKEN, ASANA_BASE_URL, ASANA_TIMEOUT and ASANA_MAX_RETRIES.
+func NewClientFromEnv() *Client {
+	c := NewClient(os.Getenv("ASANA_TOKEN"))
+	if v := os.Getenv("ASANA_BASE_URL"); v != "" {
+		c.BaseURL = strings.TrimRight(v, "/")
+	}
+	if v := os.Getenv("ASANA_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			c.HTTPClient.Timeout = d
+		} else {
+			fmt.Println("Invalid ASANA_TIMEOUT, using default:", v)
+		}
+	}
+	if v := os.Getenv("ASANA_MAX_RETRIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			c.MaxRetries = n
+		}
+	}
+	return c
+}
+
+var (
+	defaultClient     *Client
+	defaultClientOnce sync.Once
+)
+
+// DefaultClient is the shared client built from the environment on first use.
+func DefaultClient() *Client {
+	defaultClientOnce.Do(func() {
+		defaultClient = NewClientFromEnv()
+	})
+	return defaultClient
+}
+
+// get performs a GET on BaseURL+path and decodes the JSON response into out.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	requestURL := c.BaseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		body, status, header, err := c.do(ctx, requestURL)
+		if err == nil && status >= 200 && status < 300 {
+			if err := json.Unmarshal(body, out); err != nil {
+				return fmt.Errorf("error unmarshalling asana response %s: %w", path, err)
+			}
+			return nil
+		}
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			wait = c.backoff(attempt)
+		case status == http.StatusTooManyRequests:
+			wait = c.backoff(attempt)
+			if secs, convErr := strconv.Atoi(header.Get("Retry-After")); convErr == nil && secs >= 0 {
+				wait = time.Duration(secs) * time.Second
+			}
+		case status >= 500:
+			wait = c.backoff(attempt)
+		default:
+			return &APIError{StatusCode: status, Path: path, Body: string(body)}
+		}
+
+		if attempt >= c.MaxRetries {
+			if err != nil {
+				return fmt.Errorf("asana request %s: %w", path, err)
+			}
+			return &APIError{StatusCode: status, Path: path, Body: string(body)}
+		}
+		fmt.Printf("Asana request %s failed (status=%d, err=%v), retrying in %s\n", path, status, err, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, requestURL string) ([]byte, int, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, resp.Header, err
+	}
+	return body, resp.StatusCode, resp.Header, nil
+}
+
+// backoff is 1s, 2s, 4s, ... capped at MaxBackoff.
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := time.Second << min(attempt, 16)
+	if c.MaxBackoff > 0 && wait > c.MaxBackoff {
+		wait = c.MaxBackoff
+	}
+	return wait
+}
+
+// IsNotFound reports whether err is an Asana 404, e.g. for a deleted task.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// getPages follows next_page.offset until every page of a collection is read.
+func getPages[T any](ctx context.Context, c *Client, path string, query url.Values) ([]T, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("limit", strconv.Itoa(pageLimit))
+
+	var all []T
+	for {
+		var page struct {
+			Data     []T       `json:"data"`
+			NextPage *NextPage `json:"next_page"`
+		}
+		if err := c.get(ctx, path, query, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Data...)
+		if page.NextPage == nil || page.NextPage.Offset == "" {
+			return all, nil
+		}
+		query.Set("offset", page.NextPage.Offset)
+	}
+}
+
+/// ==== Endpoints ====
+
+func (c *Client) FetchProject(ctx context.Context, projectGID string) (*Project, error) {
+	var resp struct {
+		Data Project `json:"data"`
+	}
+	if err := c.get(ctx, "/projects/"+url.PathEscape(projectGID), url.Values{"opt_fields": {"name"}}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (c *Client) FetchSections(ctx context.Context, projectGID string) ([]Section, error) {
+	return getPages[Section](ctx, c, "/projects/"+url.PathEscape(projectGID)+"/sections", url.Values{"opt_fields": {"name"}})
+}
+
+func (c *Client) FetchTask(ctx context.Context, taskGID string) (*Task, error) {
+	var resp struct {
+		Data Task `json:"data"`
+	}
+	if err := c.get(ctx, "/tasks/"+url.PathEscape(taskGID), url.Values{"opt_fields": {taskFields}}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// FetchProjectTasks lists the tasks of a project that are incomplete or were
+// completed after completedSince (Asana's own filter; zero means every task).
+func (c *Client) FetchProjectTasks(ctx context.Context, projectGID string, completedSince time.Time) ([]Task, error) {
+	return getPages[Task](ctx, c, "/projects/"+url.PathEscape(projectGID)+"/tasks", taskQuery(completedSince))
+}
+
+// FetchSectionTasks is FetchProjectTasks narrowed to one section.
+func (c *Client) FetchSectionTasks(ctx context.Context, sectionGID string, completedSince time.Time) ([]Task, error) {
+	return getPages[Task](ctx, c, "/sections/"+url.PathEscape(sectionGID)+"/tasks", taskQuery(completedSince))
+}
+
+func (c *Client) FetchSubtasks(ctx context.Context, taskGID string) ([]Task, error) {
+	return getPages[Task](ctx, c, "/tasks/"+url.PathEscape(taskGID)+"/subtasks", url.Values{"opt_fields": {taskFields}})
+}
+
+func taskQuery(completedSince time.Time) url.Values {
+	query := url.Values{"opt_fields": {taskFields}}
+	if !completedSince.IsZero() {
+		query.Set("completed_since", completedSince.UTC().Format(time.RFC3339))
+	}
+	return query
+}
@@ -0,0 +1,109 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"performance-dashboard-backend/internal/syncconfig"
+)
+
+// fakeAsana serves the tasks of project p1 two per page, following the offset
+// query parameter, and records the queries it was sent.
+func fakeAsana(t *testing.T, tasks ...map[string]any) (*Client, *[]string) {
+	t.Helper()
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/projects/p1/tasks" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected request %s with Authorization %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		query := r.URL.Query()
+		queries = append(queries, query.Get("offset"))
+		if query.Get("limit") == "" || query.Get("opt_fields") == "" {
+			t.Errorf("page request without limit or opt_fields: %s", r.URL.RawQuery)
+		}
+
+		start, _ := strconv.Atoi(query.Get("offset"))
+		end := min(start+2, len(tasks))
+		page := map[string]any{"data": tasks[start:end]}
+		if end < len(tasks) {
+			page["next_page"] = map[string]any{"offset": strconv.Itoa(end)}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient("token")
+	c.BaseURL = srv.URL
+	c.MaxRetries = 0
+	return c, &queries
+}
+
+// asanaTask is a tagged task with the fields the test team scores, completed at
+// completedAt (nil for an incomplete task).
+func asanaTask(gid string, completedAt *time.Time) map[string]any {
+	task := map[string]any{
+		"gid":       gid,
+		"name":      "Task " + gid,
+		"completed": completedAt != nil,
+		"assignee":  map[string]any{"gid": "u1", "email": "a@x.com"},
+		"custom_fields": []map[string]any{
+			{"name": "Difficulty", "number_value": 3},
+			{"name": "Project", "enum_value": map[string]any{"name": "P1 Game"}},
+		},
+	}
+	if completedAt != nil {
+		task["completed_at"] = completedAt.Format(time.RFC3339)
+	}
+	return task
+}
+
+func TestFetchProjectTasksPages(t *testing.T) {
+	done := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	c, queries := fakeAsana(t, asanaTask("1", &done), asanaTask("2", nil), asanaTask("3", &done))
+
+	tasks, err := c.FetchProjectTasks(context.Background(), "p1", done.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 3 || tasks[0].GID != "1" || tasks[2].GID != "3" {
+		t.Fatalf("got %+v, want the tasks of both pages in order", tasks)
+	}
+	if len(*queries) != 2 || (*queries)[0] != "" || (*queries)[1] != "2" {
+		t.Errorf("offsets sent %q, want the first page then next_page.offset", *queries)
+	}
+}
+
+func TestFetchCompletedTasksWindow(t *testing.T) {
+	from := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	at := func(d time.Time) *time.Time { return &d }
+	c, _ := fakeAsana(t,
+		asanaTask("before", at(from.Add(-time.Minute))),
+		asanaTask("first", at(from)),
+		asanaTask("open", nil),
+		asanaTask("inside", at(to.Add(-time.Minute))),
+		asanaTask("at-end", at(to)),
+	)
+	team := &syncconfig.Team{Team: "Art", DifficultyField: "Difficulty", ProjectField: "Project"}
+	source := &syncconfig.Source{Tracker: syncconfig.TRACKER_ASANA, Project: "p1", TaskType: "Art"}
+
+	result, err := c.FetchCompletedTasks(context.Background(), team, source, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, task := range result.Tasks {
+		got = append(got, task.TaskID)
+	}
+	if len(got) != 2 || got[0] != "first" || got[1] != "inside" {
+		t.Errorf("kept %v, want the tasks completed in [from, to)", got)
+	}
+	if result.Fetched != 4 || result.Skipped != 2 || len(result.Rejections) != 0 {
+		t.Errorf("fetched %d, skipped %d, rejected %d; want 4, 2, 0", result.Fetched, result.Skipped, len(result.Rejections))
+	}
+}
@@ -0,0 +1,69 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+	"performance-dashboard-backend/internal/tasksource"
+)
+
+// Source is the Asana task source. A nil Client uses DefaultClient.
+type Source struct {
+	Client *Client
+}
+
+func init() {
+	tasksource.Register(Source{})
+}
+
+func (s Source) client() *Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return DefaultClient()
+}
+
+func (Source) Name() string {
+	return collectionmodels.SOURCE_ASANA
+}
+
+func (s Source) FetchWindow(ctx context.Context, team *syncconfig.Team, source *syncconfig.Source, from, to time.Time) (*tasksource.Result, error) {
+	return s.client().FetchCompletedTasks(ctx, team, source, from, to)
+}
+
+// FetchSingle fetches a task and returns its record, or nil when it is not completed.
+func (s Source) FetchSingle(ctx context.Context, team *syncconfig.Team, source *syncconfig.Source, taskID string) (*collectionmodels.CompletedTask, error) {
+	task, err := s.client().FetchTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeCompleted(task, team, source)
+}
+
+// Normalize converts a task as returned by the Asana API (the object inside "data").
+func (Source) Normalize(team *syncconfig.Team, source *syncconfig.Source, raw json.RawMessage) (*collectionmodels.CompletedTask, error) {
+	var task Task
+	if err := json.Unmarshal(raw, &task); err != nil {
+		return nil, fmt.Errorf("invalid asana task: %w", err)
+	}
+	return normalizeCompleted(&task, team, source)
+}
+
+func normalizeCompleted(task *Task, team *syncconfig.Team, source *syncconfig.Source) (*collectionmodels.CompletedTask, error) {
+	if !task.Completed {
+		return nil, nil
+	}
+	doneAt, ok := doneTime(task, source, syncconfig.Get().Location())
+	if !ok {
+		return nil, nil
+	}
+	completedTask, rejection := normalizeTask(task, team, source, doneAt)
+	if rejection != nil {
+		return nil, rejection
+	}
+	return completedTask, nil
+}
@@ -1,62 +1,72 @@
 package asana
 
-// Response wrapper for fetching lists from a space
-type ClickUpListsResponse struct {
-	Lists []ClickUpTaskListResponse `json:"lists"`
+import "time"
+
+// Structs for Asana API responses. Only the fields asked for in opt_fields are set.
+
+type NextPage struct {
+	Offset string `json:"offset"`
+	Path   string `json:"path"`
+	URI    string `json:"uri"`
 }
 
-type ClickUpTaskListResponse struct {
-	Id   string `json:"id"`
+type Project struct {
+	GID  string `json:"gid"`
 	Name string `json:"name"`
 }
 
-type Folder struct {
-	Id     string `json:"id"`
-	Name   string `json:"name"`
-	Hidden bool   `json:"hidden"`
-	Access bool   `json:"access"`
+type Section struct {
+	GID  string `json:"gid"`
+	Name string `json:"name"`
 }
 
-type Space struct {
-	Id     string `json:"id"`
-	Name   string `json:"name"`
-	Access bool   `json:"access"`
+type User struct {
+	GID   string `json:"gid"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
 }
 
-type Priority struct {
-	Priority string `json:"priority"`
-	Color    string `json:"color"`
+type Tag struct {
+	GID  string `json:"gid"`
+	Name string `json:"name"`
 }
 
-// Structs for ClickUp API response
-type ClickUpResponse struct {
-	Tasks    []Task `json:"tasks"`
-	LastPage bool   `json:"last_page"`
+type Membership struct {
+	Project *Project `json:"project"`
+	Section *Section `json:"section"`
 }
 
 type Task struct {
-	Id           string        `json:"id"`
+	GID          string        `json:"gid"`
 	Name         string        `json:"name"`
-	Status       *Status       `json:"status"`
-	DueDate      string        `json:"due_date"`
-	Assignees    []Assignee    `json:"assignees"`
+	Completed    bool          `json:"completed"`
+	CompletedAt  *time.Time    `json:"completed_at"`
+	PermalinkURL string        `json:"permalink_url"`
+	NumSubtasks  int           `json:"num_subtasks"`
+	Assignee     *User         `json:"assignee"`
+	Tags         []Tag         `json:"tags"`
+	Memberships  []Membership  `json:"memberships"`
 	CustomFields []CustomField `json:"custom_fields"`
 }
 
-type Status struct {
-	Status string `json:"status"`
-	Type   string `json:"type"`
+type EnumOption struct {
+	GID  string `json:"gid"`
+	Name string `json:"name"`
 }
 
-type Assignee struct {
-	Id       int    `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
+type DateValue struct {
+	Date     string     `json:"date"`
+	DateTime *time.Time `json:"date_time"`
 }
 
 type CustomField struct {
-	Id         string      `json:"id"`
-	Name       string      `json:"name"`
-	Value      interface{} `json:"value"`
-	TypeConfig interface{} `json:"type_config"`
+	GID             string       `json:"gid"`
+	Name            string       `json:"name"`
+	Type            string       `json:"type"`
+	NumberValue     *float64     `json:"number_value"`
+	TextValue       *string      `json:"text_value"`
+	DisplayValue    *string      `json:"display_value"`
+	DateValue       *DateValue   `json:"date_value"`
+	EnumValue       *EnumOption  `json:"enum_value"`
+	MultiEnumValues []EnumOption `json:"multi_enum_values"`
 }
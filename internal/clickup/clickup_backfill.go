@@ -0,0 +1,100 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+)
+
+type BackfillWeek struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Fetched  int       `json:"fetched"`
+	Inserted int64     `json:"inserted"`
+	Existing int64     `json:"existing"`
+	Error    string    `json:"error,omitempty"`
+	// Diff is only set on a dry run, which writes nothing.
+	Diff *SyncDiff `json:"diff,omitempty"`
+}
+
+type BackfillReport struct {
+	Team     string          `json:"team"`
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Weeks    []*BackfillWeek `json:"weeks"`
+	Inserted int64           `json:"inserted"`
+	Existing int64           `json:"existing"`
+	Failed   int             `json:"failed_weeks"`
+	RunID    string          `json:"run_id,omitempty"`
+}
+
+// Backfill imports a team's completed tasks for every weekly window overlapping
+// [from, to). Each week is fetched with the same filters as the weekly sync and
+// upserted, so running it twice does not duplicate records. A failing week is
+// reported and the walk continues; progress, when set, is called after each week.
+// With dryRun each week is diffed against the stored records instead.
+func Backfill(ctx context.Context, teamName string, from, to time.Time, dryRun bool, trigger string, progress func(*BackfillWeek)) (*BackfillReport, error) {
+	cfg := syncconfig.Get()
+	team := cfg.Team(teamName)
+	if team == nil {
+		return nil, fmt.Errorf("unknown team %q", teamName)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	report := &BackfillReport{Team: team.Team, From: from, To: to}
+	var run *syncRun
+	if !dryRun {
+		run = startSyncRun(collectionmodels.SYNC_KIND_BACKFILL, trigger, team.Team, from, to)
+		report.RunID = run.ID.Hex()
+	}
+	for start := cfg.WeekStart(team, from); start.Before(to); start = start.AddDate(0, 0, 7) {
+		if err := ctx.Err(); err != nil {
+			if run != nil {
+				run.finish(err)
+			}
+			return report, err
+		}
+		week := &BackfillWeek{From: start, To: start.AddDate(0, 0, 7)}
+		var err error
+		if dryRun {
+			week.Diff, err = DryRunTeam(ctx, team, week.From, week.To)
+			if err == nil {
+				week.Fetched = len(week.Diff.New) + len(week.Diff.Changed) + week.Diff.Unchanged
+			}
+		} else {
+			var result *TeamTasks
+			result, err = GetTasksForTeam(ctx, team, week.From, week.To)
+			if err == nil {
+				run.addTasks(result)
+				week.Fetched = len(result.Tasks)
+				week.Inserted, week.Existing, err = StoreCompletedTasks(result.Tasks)
+				run.Inserted += week.Inserted
+				if err == nil {
+					recordTeamRejections(result)
+				}
+			}
+		}
+		if err != nil {
+			week.Error = err.Error()
+			report.Failed++
+			if run != nil {
+				run.addError(fmt.Errorf("week %s: %w", week.From.Format("2006-01-02"), err))
+			}
+		}
+		report.Weeks = append(report.Weeks, week)
+		report.Inserted += week.Inserted
+		report.Existing += week.Existing
+		if progress != nil {
+			progress(week)
+		}
+	}
+	if run != nil {
+		run.finish(nil)
+	}
+	return report, nil
+}
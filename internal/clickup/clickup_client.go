@@ -0,0 +1,324 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBaseURL    = "https://api.clickup.com/api/v2"
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 5
+	defaultMaxBackoff = time.Minute
+)
+
+// Client talks to the ClickUp REST API. It retries 429 responses after the
+// X-RateLimit-Reset time and 5xx responses / network errors with exponential
+// backoff. BaseURL can point at a local fake server.
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+	MaxRetries int
+	MaxBackoff time.Duration
+
+	metrics clientMetrics
+}
+
+type clientMetrics struct {
+	requests    atomic.Int64
+	retries     atomic.Int64
+	rateLimited atomic.Int64
+	failures    atomic.Int64
+	totalMillis atomic.Int64
+}
+
+// ClientMetrics is a snapshot of the request counters of a Client.
+type ClientMetrics struct {
+	Requests     int64   `json:"requests"`
+	Retries      int64   `json:"retries"`
+	RateLimited  int64   `json:"rate_limited"`
+	Failures     int64   `json:"failures"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// APIError is returned for a non-2xx response that was not (or no longer) retried.
+type APIError struct {
+	StatusCode int
+	Path       string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("clickup request %s failed (status=%d): %s", e.Path, e.StatusCode, e.Body)
+}
+
+func NewClient(token string) *Client {
+	return &Client{
+		BaseURL:    defaultBaseURL,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: defaultTimeout},
+		MaxRetries: defaultMaxRetries,
+		MaxBackoff: defaultMaxBackoff,
+	}
+}
+
+// NewClientFromEnv reads CLICKUP_TOKEN, CLICKUP_BASE_URL, CLICKUP_TIMEOUT and CLICKUP_MAX_RETRIES.
+func NewClientFromEnv() *Client {
+	c := NewClient(os.Getenv("CLICKUP_TOKEN"))
+	if v := os.Getenv("CLICKUP_BASE_URL"); v != "" {
+		c.BaseURL = strings.TrimRight(v, "/")
+	}
+	if v := os.Getenv("CLICKUP_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			c.HTTPClient.Timeout = d
+		} else {
+			fmt.Println("Invalid CLICKUP_TIMEOUT, using default:", v)
+		}
+	}
+	if v := os.Getenv("CLICKUP_MAX_RETRIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			c.MaxRetries = n
+		}
+	}
+	return c
+}
+
+var (
+	defaultClient     *Client
+	defaultClientOnce sync.Once
+)
+
+// DefaultClient is the shared client built from the environment on first use,
+// after main has loaded .env.
+func DefaultClient() *Client {
+	defaultClientOnce.Do(func() {
+		defaultClient = NewClientFromEnv()
+	})
+	return defaultClient
+}
+
+func (c *Client) Metrics() ClientMetrics {
+	m := ClientMetrics{
+		Requests:    c.metrics.requests.Load(),
+		Retries:     c.metrics.retries.Load(),
+		RateLimited: c.metrics.rateLimited.Load(),
+		Failures:    c.metrics.failures.Load(),
+	}
+	if m.Requests > 0 {
+		m.AvgLatencyMs = float64(c.metrics.totalMillis.Load()) / float64(m.Requests)
+	}
+	return m
+}
+
+// get performs a GET on BaseURL+path and decodes the JSON response into out.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	requestURL := c.BaseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		body, status, header, err := c.do(ctx, requestURL)
+		if err == nil && status >= 200 && status < 300 {
+			if err := json.Unmarshal(body, out); err != nil {
+				return fmt.Errorf("error unmarshalling clickup response %s: %w", path, err)
+			}
+			return nil
+		}
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				c.metrics.failures.Add(1)
+				return ctx.Err()
+			}
+			wait = c.backoff(attempt)
+		case status == http.StatusTooManyRequests:
+			c.metrics.rateLimited.Add(1)
+			wait = c.rateLimitWait(header, attempt)
+		case status >= 500:
+			wait = c.backoff(attempt)
+		default:
+			c.metrics.failures.Add(1)
+			return &APIError{StatusCode: status, Path: path, Body: string(body)}
+		}
+
+		if attempt >= c.MaxRetries {
+			c.metrics.failures.Add(1)
+			if err != nil {
+				return fmt.Errorf("clickup request %s: %w", path, err)
+			}
+			return &APIError{StatusCode: status, Path: path, Body: string(body)}
+		}
+		c.metrics.retries.Add(1)
+		fmt.Printf("ClickUp request %s failed (status=%d, err=%v), retrying in %s\n", path, status, err, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			c.metrics.failures.Add(1)
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, requestURL string) ([]byte, int, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	req.Header.Set("Authorization", c.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	c.metrics.requests.Add(1)
+	defer func() { c.metrics.totalMillis.Add(time.Since(start).Milliseconds()) }()
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, resp.Header, err
+	}
+	return body, resp.StatusCode, resp.Header, nil
+}
+
+// backoff is 1s, 2s, 4s, ... capped at MaxBackoff.
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := time.Second << min(attempt, 16)
+	if c.MaxBackoff > 0 && wait > c.MaxBackoff {
+		wait = c.MaxBackoff
+	}
+	return wait
+}
+
+// rateLimitWait honours X-RateLimit-Reset (unix seconds) or Retry-After, falling back to backoff.
+func (c *Client) rateLimitWait(header http.Header, attempt int) time.Duration {
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+		if wait := time.Until(time.Unix(reset, 0)); wait > 0 {
+			if c.MaxBackoff > 0 && wait > c.MaxBackoff {
+				return c.MaxBackoff
+			}
+			return wait
+		}
+		return 0
+	}
+	if secs, err := strconv.Atoi(header.Get("Retry-After")); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return c.backoff(attempt)
+}
+
+// IsNotFound reports whether err is a ClickUp 404, e.g. for a deleted task.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+/// ==== Endpoints ====
+
+func (c *Client) FetchSingleTask(ctx context.Context, taskID string) (*ClickUpTask, error) {
+	var task ClickUpTask
+	if err := c.get(ctx, "/task/"+url.PathEscape(taskID), nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (c *Client) FetchSpaceLists(ctx context.Context, spaceID string) ([]ClickUpTaskListResponse, error) {
+	var listsResp ClickUpWorkSpaceListResponse
+	if err := c.get(ctx, "/space/"+url.PathEscape(spaceID)+"/list", nil, &listsResp); err != nil {
+		return nil, err
+	}
+	return listsResp.Lists, nil
+}
+
+// TaskFilter narrows the tasks fetched from a list. With DoneDateFieldID set the
+// done window is matched against that date custom field instead of date_done.
+type TaskFilter struct {
+	Completed       bool
+	Tag             string
+	IncludeSubtasks bool
+	DoneFrom        int64
+	DoneTo          int64
+	DoneDateFieldID string
+}
+
+// FetchTasksFromSpace fetches the tasks of every list in a space. A failing list
+// fails the whole call so a sync never stores a partial week.
+func (c *Client) FetchTasksFromSpace(ctx context.Context, spaceID string, filter TaskFilter) ([]ClickUpTask, error) {
+	lists, err := c.FetchSpaceLists(ctx, spaceID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch lists of space %s: %w", spaceID, err)
+	}
+
+	var allTasks []ClickUpTask
+	for _, list := range lists {
+		tasks, err := c.FetchTaskList(ctx, list.Id, filter)
+		if err != nil {
+			return nil, fmt.Errorf("fetch tasks of list %s (%s): %w", list.Id, list.Name, err)
+		}
+		allTasks = append(allTasks, tasks...)
+	}
+	return allTasks, nil
+}
+
+func (c *Client) FetchTaskList(ctx context.Context, listID string, filter TaskFilter) ([]ClickUpTask, error) {
+	query := url.Values{}
+	query.Set("include_closed", "true")
+	query.Set("archived", "false")
+	if tag := strings.TrimSpace(filter.Tag); tag != "" {
+		query.Add("tags[]", tag)
+	}
+	if filter.DoneDateFieldID != "" {
+		query.Set("custom_fields", fmt.Sprintf("[{\"field_id\":\"%s\",\"operator\":\">\",\"value\":\"%d\"}]", filter.DoneDateFieldID, filter.DoneFrom))
+		return c.fetchListPages(ctx, listID, query)
+	}
+	if filter.Completed {
+		query.Add("statuses[]", "COMPLETED")
+	}
+	if filter.IncludeSubtasks {
+		query.Set("subtasks", "true")
+	}
+	if filter.DoneFrom > 0 {
+		query.Set("date_done_gt", strconv.FormatInt(filter.DoneFrom, 10))
+	}
+	if filter.DoneTo > 0 {
+		query.Set("date_done_lt", strconv.FormatInt(filter.DoneTo, 10))
+	}
+	return c.fetchListPages(ctx, listID, query)
+}
+
+func (c *Client) fetchListPages(ctx context.Context, listID string, query url.Values) ([]ClickUpTask, error) {
+	var allTasks []ClickUpTask
+	for page := 0; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var listResp ClickUpResponse
+		if err := c.get(ctx, "/list/"+url.PathEscape(listID)+"/task", query, &listResp); err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
+		allTasks = append(allTasks, listResp.Tasks...)
+		if listResp.LastPage || len(listResp.Tasks) == 0 {
+			break
+		}
+	}
+	return allTasks, nil
+}
@@ -0,0 +1,124 @@
+package clickup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClickUp answers /task/t1 with the given responses in turn, repeating the
+// last one, and counts the calls.
+func fakeClickUp(t *testing.T, responses ...func(w http.ResponseWriter)) (*Client, *atomic.Int64) {
+	t.Helper()
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/task/t1" || r.Header.Get("Authorization") != "token" {
+			t.Errorf("unexpected request %s with Authorization %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		n := int(calls.Add(1))
+		responses[min(n, len(responses))-1](w)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient("token")
+	c.BaseURL = srv.URL
+	c.MaxRetries = 2
+	c.MaxBackoff = 10 * time.Millisecond
+	return c, &calls
+}
+
+func respondStatus(code int, header ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(header); i += 2 {
+			w.Header().Set(header[i], header[i+1])
+		}
+		w.WriteHeader(code)
+		w.Write([]byte(`{"err": "` + http.StatusText(code) + `"}`))
+	}
+}
+
+func respondOK(w http.ResponseWriter) {
+	w.Write([]byte(`{"id": "t1", "name": "Task"}`))
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []func(w http.ResponseWriter)
+		wantErr   int // status of the returned APIError, 0 for success
+		want      ClientMetrics
+	}{
+		{
+			name:      "429 until X-RateLimit-Reset",
+			responses: []func(w http.ResponseWriter){respondStatus(http.StatusTooManyRequests, "X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)), respondOK},
+			want:      ClientMetrics{Requests: 2, Retries: 1, RateLimited: 1},
+		},
+		{
+			name:      "429 with Retry-After",
+			responses: []func(w http.ResponseWriter){respondStatus(http.StatusTooManyRequests, "Retry-After", "0"), respondOK},
+			want:      ClientMetrics{Requests: 2, Retries: 1, RateLimited: 1},
+		},
+		{
+			name:      "5xx retried then given up",
+			responses: []func(w http.ResponseWriter){respondStatus(http.StatusBadGateway), respondStatus(http.StatusServiceUnavailable)},
+			wantErr:   http.StatusServiceUnavailable,
+			want:      ClientMetrics{Requests: 3, Retries: 2, Failures: 1},
+		},
+		{
+			name:      "5xx then success",
+			responses: []func(w http.ResponseWriter){respondStatus(http.StatusInternalServerError), respondOK},
+			want:      ClientMetrics{Requests: 2, Retries: 1},
+		},
+		{
+			name:      "4xx not retried",
+			responses: []func(w http.ResponseWriter){respondStatus(http.StatusNotFound), respondOK},
+			wantErr:   http.StatusNotFound,
+			want:      ClientMetrics{Requests: 1, Failures: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, calls := fakeClickUp(t, tt.responses...)
+			start := time.Now()
+			task, err := c.FetchSingleTask(context.Background(), "t1")
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("took %s; waits are not capped at MaxBackoff", elapsed)
+			}
+
+			if tt.wantErr == 0 {
+				if err != nil || task.Id != "t1" {
+					t.Fatalf("task %+v, error %v", task, err)
+				}
+			} else {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantErr {
+					t.Fatalf("error %v, want an APIError with status %d", err, tt.wantErr)
+				}
+			}
+			if calls.Load() != tt.want.Requests {
+				t.Errorf("server saw %d requests, want %d", calls.Load(), tt.want.Requests)
+			}
+			got := c.Metrics()
+			got.AvgLatencyMs = 0
+			if got != tt.want {
+				t.Errorf("metrics %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	c, _ := fakeClickUp(t, respondStatus(http.StatusNotFound))
+	if _, err := c.FetchSingleTask(context.Background(), "t1"); !IsNotFound(err) {
+		t.Errorf("error %v not reported as not found", err)
+	}
+	c, _ = fakeClickUp(t, respondStatus(http.StatusUnauthorized))
+	if _, err := c.FetchSingleTask(context.Background(), "t1"); IsNotFound(err) {
+		t.Errorf("error %v reported as not found", err)
+	}
+}
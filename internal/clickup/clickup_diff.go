@@ -0,0 +1,96 @@
+package clickup
+
+import (
+	"context"
+	"os"
+	"time"
+
+	database "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+)
+
+// ChangedTask is a task that is already stored but would be stored differently.
+type ChangedTask struct {
+	TaskID string                          `json:"task_id"`
+	Fields []string                        `json:"fields"`
+	Before *collectionmodels.CompletedTask `json:"before"`
+	After  *collectionmodels.CompletedTask `json:"after"`
+}
+
+// SyncDiff is what a sync of one team and window would change in the
+// completed-task collection.
+type SyncDiff struct {
+	Team      string                            `json:"team"`
+	From      time.Time                         `json:"from"`
+	To        time.Time                         `json:"to"`
+	New       []*collectionmodels.CompletedTask `json:"new"`
+	Changed   []*ChangedTask                    `json:"changed"`
+	Missing   []*collectionmodels.CompletedTask `json:"missing"`
+	Unchanged int                               `json:"unchanged"`
+}
+
+func (d *SyncDiff) Empty() bool {
+	return len(d.New) == 0 && len(d.Changed) == 0 && len(d.Missing) == 0
+}
+
+// DryRunTeam fetches a team's window exactly like SyncTeam and compares it with
+// the stored records of the same weeks, without writing anything.
+func DryRunTeam(ctx context.Context, team *syncconfig.Team, from, to time.Time) (*SyncDiff, error) {
+	result, err := GetTasksForTeam(ctx, team, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := syncconfig.Get()
+	stored, err := collectionmodels.GetCompletedTasksByDateRange(database.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), true, team.Team,
+		cfg.DoneWeek(team, from), cfg.DoneWeek(team, to.Add(-time.Millisecond)))
+	if err != nil {
+		return nil, err
+	}
+
+	diff := DiffCompletedTasks(result.Tasks, stored)
+	diff.Team, diff.From, diff.To = team.Team, from, to
+	return diff, nil
+}
+
+// DiffCompletedTasks matches fetched and stored records by task id, preferring
+// the record with the same assignee when a task has several. Stored records with
+// no fetched counterpart are reported as missing: they would not be removed by a
+// sync, but they no longer match ClickUp.
+func DiffCompletedTasks(fetched []*collectionmodels.CompletedTask, stored []collectionmodels.CompletedTask) *SyncDiff {
+	byTaskID := make(map[string][]*collectionmodels.CompletedTask, len(stored))
+	for i := range stored {
+		byTaskID[stored[i].TaskID] = append(byTaskID[stored[i].TaskID], &stored[i])
+	}
+	matched := make(map[*collectionmodels.CompletedTask]bool, len(stored))
+
+	diff := &SyncDiff{}
+	for _, task := range fetched {
+		var match *collectionmodels.CompletedTask
+		for _, candidate := range byTaskID[task.TaskID] {
+			if matched[candidate] {
+				continue
+			}
+			if match == nil || candidate.AssigneeID == task.AssigneeID {
+				match = candidate
+			}
+		}
+		if match == nil {
+			diff.New = append(diff.New, task)
+			continue
+		}
+		matched[match] = true
+		if fields := collectionmodels.ChangedFields(match, task); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, &ChangedTask{TaskID: task.TaskID, Fields: fields, Before: match, After: task})
+		} else {
+			diff.Unchanged++
+		}
+	}
+	for i := range stored {
+		if !matched[&stored[i]] {
+			diff.Missing = append(diff.Missing, &stored[i])
+		}
+	}
+	return diff
+}
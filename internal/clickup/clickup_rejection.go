@@ -0,0 +1,51 @@
+package clickup
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	database "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+	"performance-dashboard-backend/internal/tasksource"
+)
+
+func newRejection(task *ClickUpTask, team *syncconfig.Team, assigneeID, code, reason string) *tasksource.RejectionError {
+	return &tasksource.RejectionError{Rejection: collectionmodels.TaskRejection{
+		TaskID:     task.Id,
+		TaskName:   task.Name,
+		URL:        task.URL,
+		Team:       team.Team,
+		AssigneeID: assigneeID,
+		Code:       code,
+		Reason:     reason,
+	}}
+}
+
+// RecordRejections stores rejections for the data quality report. Errors are
+// logged; a failed report write never fails a sync or a webhook.
+func RecordRejections(origin string, rejections []collectionmodels.TaskRejection) {
+	if err := collectionmodels.UpsertRejectedTasks(database.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TASK_REJECTION"), origin, rejections, time.Now().UTC()); err != nil {
+		fmt.Println("Error recording task rejections:", err)
+	}
+}
+
+// ResolveRejections closes the open rejections of tasks that were accepted (or
+// are no longer done) since they were rejected.
+func ResolveRejections(taskIDs []string) {
+	if _, err := collectionmodels.ResolveRejectedTasks(database.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_TASK_REJECTION"), taskIDs, time.Now().UTC()); err != nil {
+		fmt.Println("Error resolving task rejections:", err)
+	}
+}
+
+// recordTeamRejections updates the report after a stored sync: new rejections are
+// recorded and the accepted tasks' earlier rejections are resolved.
+func recordTeamRejections(result *TeamTasks) {
+	RecordRejections(collectionmodels.REJECTION_ORIGIN_SYNC, result.Rejections)
+	taskIDs := make([]string, 0, len(result.Tasks))
+	for _, task := range result.Tasks {
+		taskIDs = append(taskIDs, task.TaskID)
+	}
+	ResolveRejections(taskIDs)
+}
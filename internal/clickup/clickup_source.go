@@ -0,0 +1,128 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+	"performance-dashboard-backend/internal/tasksource"
+)
+
+// Source is the ClickUp task source. A nil Client uses DefaultClient.
+type Source struct {
+	Client *Client
+}
+
+func init() {
+	tasksource.Register(Source{})
+}
+
+func (s Source) client() *Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return DefaultClient()
+}
+
+func (Source) Name() string {
+	return collectionmodels.SOURCE_CLICKUP
+}
+
+// FetchWindow fetches the completed tasks of a space (narrowed to the source's tag)
+// done in [from, to).
+func (s Source) FetchWindow(ctx context.Context, team *syncconfig.Team, source *syncconfig.Source, from, to time.Time) (*tasksource.Result, error) {
+	// ClickUp's date_done_gt is strictly greater; step back 1ms so tasks done exactly at `from` are included.
+	fromMillis, toMillis := from.UnixMilli()-1, to.UnixMilli()
+
+	res, err := s.client().FetchTasksFromSpace(ctx, source.Space, TaskFilter{
+		Completed:       true,
+		Tag:             source.Tag,
+		IncludeSubtasks: team.IncludeSubtasks,
+		DoneFrom:        fromMillis,
+		DoneTo:          toMillis,
+		DoneDateFieldID: source.DoneDateFieldID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s tasks from space %s (tag %q): %w", team.Team, source.Space, source.Tag, err)
+	}
+
+	result := &tasksource.Result{Fetched: len(res)}
+	var accepted []*collectionmodels.CompletedTask
+	for i := range res {
+		task := &res[i]
+		if source.DoneDateFieldID == "" {
+			if task.DateDone == "" {
+				result.Skipped++
+				continue
+			}
+		} else if doneMillis, ok := customFieldMillis(task, source.DoneDateFieldID); !ok || doneMillis == 0 || doneMillis > toMillis {
+			result.Skipped++
+			continue
+		}
+
+		completedTask, err := normalizeTask(task, team, source)
+		if err != nil {
+			fmt.Println("Rejected task:", err)
+			var rejectionErr *tasksource.RejectionError
+			if errors.As(err, &rejectionErr) {
+				result.Rejections = append(result.Rejections, rejectionErr.Rejection)
+			} else {
+				result.Rejections = append(result.Rejections, collectionmodels.TaskRejection{TaskID: task.Id, TaskName: task.Name, URL: task.URL, Team: team.Team, Reason: err.Error(), Week: taskWeek(task, team, source, syncconfig.Get())})
+			}
+			continue
+		}
+		accepted = append(accepted, completedTask)
+	}
+	if source.DedupeByName {
+		deduped := collectionmodels.DedupeCompletedTasksByName(accepted)
+		result.Skipped += len(accepted) - len(deduped)
+		accepted = deduped
+	}
+	result.Tasks = accepted
+	return result, nil
+}
+
+// FetchSingle fetches a task and returns its record, or nil when the task is not
+// done (for the concept webhook: not tagged concept-done).
+func (s Source) FetchSingle(ctx context.Context, team *syncconfig.Team, source *syncconfig.Source, taskID string) (*collectionmodels.CompletedTask, error) {
+	task, err := s.client().FetchSingleTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	done := IsTaskDone(task)
+	if team.Webhook == syncconfig.WEBHOOK_CONCEPT {
+		done = IsConceptDone(task)
+	}
+	if !done {
+		return nil, nil
+	}
+	return normalizeTask(task, team, source)
+}
+
+// Normalize converts a task as returned by the ClickUp API.
+func (Source) Normalize(team *syncconfig.Team, source *syncconfig.Source, raw json.RawMessage) (*collectionmodels.CompletedTask, error) {
+	var task ClickUpTask
+	if err := json.Unmarshal(raw, &task); err != nil {
+		return nil, fmt.Errorf("invalid clickup task: %w", err)
+	}
+	return normalizeTask(&task, team, source)
+}
+
+// normalizeTask builds the record of a task and files it under its work week.
+func normalizeTask(task *ClickUpTask, team *syncconfig.Team, source *syncconfig.Source) (*collectionmodels.CompletedTask, error) {
+	week := taskWeek(task, team, source, syncconfig.Get())
+	completedTask, err := BuildCompletedTask(task, team, source)
+	if err != nil {
+		var rejectionErr *tasksource.RejectionError
+		if errors.As(err, &rejectionErr) {
+			rejectionErr.Rejection.Week = week
+		}
+		return nil, err
+	}
+	completedTask.DoneDate = week
+	return completedTask, nil
+}
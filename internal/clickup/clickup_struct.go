@@ -1,6 +1,7 @@
 package clickup
 
 type ClickUpAssignee struct {
+	ID       int64  `json:"id"`
 	Email    string `json:"email"`
 	UserName string `json:"username"`
 }
@@ -71,6 +72,7 @@ type ClickUpTag struct {
 type ClickUpTask struct {
 	Id           string               `json:"id"`
 	Name         string               `json:"name"`
+	URL          string               `json:"url"`
 	DateDone     string               `json:"date_done"`
 	Assignees    []ClickUpAssignee    `json:"assignees"`
 	CustomFields []ClickUpCustomField `json:"custom_fields"`
@@ -91,17 +93,25 @@ type ClickUpWebhookUser struct {
 }
 
 type ClickUpHistoryItem struct {
-	Field  string             `json:"field"`
-	User   ClickUpWebhookUser `json:"user"`
-	Before interface{}        `json:"before"`
-	After  interface{}        `json:"after"`
-	Date   string             `json:"date"`
+	ID       string             `json:"id"`
+	Type     int                `json:"type"`
+	Field    string             `json:"field"`
+	ParentID string             `json:"parent_id"`
+	User     ClickUpWebhookUser `json:"user"`
+	Before   interface{}        `json:"before"`
+	After    interface{}        `json:"after"`
+	Date     string             `json:"date"`
+	Data     interface{}        `json:"data"`
 }
 
 type ClickUpWebhookPayload struct {
 	Event        string               `json:"event"`
 	TaskID       string               `json:"task_id"`
+	WebhookID    string               `json:"webhook_id"`
 	HistoryItems []ClickUpHistoryItem `json:"history_items"`
+
+	// body is the raw delivery, kept by ParseVerifiedWebhook for EventID.
+	body []byte
 }
 
 type ClickUpWorkSpaceListResponse struct {
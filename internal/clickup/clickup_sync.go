@@ -0,0 +1,254 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	database "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+	"performance-dashboard-backend/internal/tasksource"
+	util "performance-dashboard-backend/internal/utils"
+)
+
+// TeamTasks is the result of fetching a team's window across all its sources.
+type TeamTasks = tasksource.Result
+
+// SyncAllTeams syncs every team in the sync config over its current weekly window.
+// A failing team does not stop the others; the errors are joined.
+func SyncAllTeams(ctx context.Context, now time.Time, trigger string) error {
+	cfg := syncconfig.Get()
+	var errs []string
+	for _, team := range cfg.Teams {
+		from, to := cfg.Window(team, now)
+		if _, err := SyncTeam(ctx, team, from, to, trigger); err != nil {
+			fmt.Printf("Error syncing team %s: %v\n", team.Team, err)
+			errs = append(errs, team.Team+": "+err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("sync failed for %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// SyncTeam stores the tasks a team completed in [from, to) and records the run.
+// Nothing is stored when any source fails, so a week is never half-synced.
+func SyncTeam(ctx context.Context, team *syncconfig.Team, from, to time.Time, trigger string) (*collectionmodels.SyncRun, error) {
+	fmt.Println("Time Window for team", team.Team, "from", from, "to", to)
+	run := startSyncRun(collectionmodels.SYNC_KIND_TEAM, trigger, team.Team, from, to)
+
+	result, err := GetTasksForTeam(ctx, team, from, to)
+	if err == nil {
+		run.addTasks(result)
+		run.Inserted, _, err = StoreCompletedTasks(result.Tasks)
+		if err == nil {
+			recordTeamRejections(result)
+		}
+	}
+	run.finish(err)
+	return run.SyncRun, err
+}
+
+// StoreCompletedTasks upserts synced tasks; records that already exist are kept as they are.
+func StoreCompletedTasks(tasks []*collectionmodels.CompletedTask) (inserted, existing int64, err error) {
+	return collectionmodels.UpsertCompletedTasks(database.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_COMPLETED_TASK"), tasks)
+}
+
+// GetTasksForTeam fetches and converts the tasks every source of a team completed
+// in [from, to), through the task source registered for the source's tracker.
+// Tasks with missing or invalid scored fields are rejected with a reason.
+func GetTasksForTeam(ctx context.Context, team *syncconfig.Team, from, to time.Time) (*TeamTasks, error) {
+	result := &TeamTasks{}
+	for _, source := range team.Sources {
+		if !source.Enabled() {
+			continue
+		}
+		src, ok := tasksource.Get(source.Tracker)
+		if !ok {
+			return nil, fmt.Errorf("no task source registered for tracker %q (team %s)", source.Tracker, team.Team)
+		}
+		res, err := src.FetchWindow(ctx, team, source, from, to)
+		if err != nil {
+			return nil, err
+		}
+		result.Add(res)
+	}
+	return result, nil
+}
+
+// BuildCompletedTask reads the scored fields of a task as configured for its team
+// and source. DoneDate is left for the caller to decide. Field problems are
+// returned as a *tasksource.RejectionError.
+func BuildCompletedTask(task *ClickUpTask, team *syncconfig.Team, source *syncconfig.Source) (*collectionmodels.CompletedTask, error) {
+	customFieldMap := util.IndexBy(task.CustomFields, func(cf *ClickUpCustomField) string {
+		return cf.Name
+	})
+
+	var toolIndexes []int
+	if toolCustomField, ok := customFieldMap[team.ToolField]; ok && toolCustomField != nil {
+		toolFields, err := util.CoerceStruct[ClickUpToolCustomField](toolCustomField)
+		if err == nil {
+			for _, selectedToolID := range toolFields.Value {
+				for _, option := range toolFields.TypeConfig.Options {
+					if option.ID == selectedToolID {
+						if inx := GetToolIndex(option.Name); inx != -1 {
+							toolIndexes = append(toolIndexes, inx)
+						}
+						break
+					}
+				}
+			}
+		}
+	}
+
+	primary := team.AssigneeIndex(len(task.Assignees))
+	assigneeEmail := ""
+	if len(task.Assignees) > 0 {
+		assigneeEmail = task.Assignees[primary].Email
+	}
+
+	difficultField, okLevel := customFieldMap[team.DifficultyField]
+	if !okLevel || difficultField.Value == nil {
+		return nil, newRejection(task, team, assigneeEmail, collectionmodels.REJECT_MISSING_DIFFICULTY, fmt.Sprintf("%q field missing", team.DifficultyField))
+	}
+	level, ok := anyToInt(difficultField.Value)
+	if !ok {
+		return nil, newRejection(task, team, assigneeEmail, collectionmodels.REJECT_INVALID_DIFFICULTY, fmt.Sprintf("invalid %q value %v", team.DifficultyField, difficultField.Value))
+	}
+
+	projectField, okProject := customFieldMap[team.ProjectField]
+	if !okProject || projectField.Value == nil {
+		return nil, newRejection(task, team, assigneeEmail, collectionmodels.REJECT_MISSING_PROJECT, fmt.Sprintf("%q field missing", team.ProjectField))
+	}
+	projectCustomField, err := util.CoerceStruct[ClickUpProjectCustomField](projectField)
+	if err != nil {
+		return nil, newRejection(task, team, assigneeEmail, collectionmodels.REJECT_INVALID_PROJECT, fmt.Sprintf("cannot parse %q: %v", team.ProjectField, err))
+	}
+	projectIndex := projectCustomField.Value
+	if projectIndex < 0 || projectIndex >= len(projectCustomField.TypeConfig.Options) {
+		return nil, newRejection(task, team, assigneeEmail, collectionmodels.REJECT_INVALID_PROJECT, fmt.Sprintf("invalid %q option index %d", team.ProjectField, projectIndex))
+	}
+	projectName := projectCustomField.TypeConfig.Options[projectIndex].Name
+	if spaceIdx := strings.Index(projectName, " "); spaceIdx != -1 {
+		projectName = projectName[spaceIdx+1:]
+	}
+
+	if assigneeEmail == "" {
+		return nil, newRejection(task, team, "", collectionmodels.REJECT_NO_ASSIGNEE, "no assignee")
+	}
+
+	completedTask := &collectionmodels.CompletedTask{
+		TaskID:     task.Id,
+		TaskName:   task.Name,
+		AssigneeID: memberID(task.Assignees[primary]),
+		Tool:       toolIndexes,
+		Level:      level,
+		Project:    projectName,
+		Team:       team.Team,
+		TaskType:   source.TaskType,
+		Source:     collectionmodels.SOURCE_CLICKUP,
+	}
+	if err := splitCredit(completedTask, task, team, primary, customFieldMap); err != nil {
+		return nil, newRejection(task, team, assigneeEmail, collectionmodels.REJECT_INVALID_CREDIT, err.Error())
+	}
+	return completedTask, nil
+}
+
+// splitCredit shares the task between its assignees by the team's credit rule.
+// primary is the index of the assignee completedTask is credited to, who takes
+// the primary share; assignees without an email are left out.
+func splitCredit(completedTask *collectionmodels.CompletedTask, task *ClickUpTask, team *syncconfig.Team, primary int, customFieldMap map[string]*ClickUpCustomField) error {
+	if team.Credit == nil || team.Credit.Mode == syncconfig.CREDIT_SINGLE {
+		return nil
+	}
+	if primary < 0 || primary >= len(task.Assignees) || task.Assignees[primary].Email == "" {
+		return fmt.Errorf("primary assignee has no email")
+	}
+	primaryAt := 0
+	emails := make([]string, 0, len(task.Assignees))
+	members := make([]string, 0, len(task.Assignees))
+	for i, assignee := range task.Assignees {
+		if assignee.Email == "" {
+			continue
+		}
+		if i == primary {
+			primaryAt = len(emails)
+		}
+		emails = append(emails, assignee.Email)
+		members = append(members, memberID(assignee))
+	}
+
+	field := ""
+	if cf, ok := customFieldMap[team.Credit.Field]; ok && cf != nil && cf.Value != nil {
+		field = fmt.Sprint(cf.Value)
+	}
+	// The credit field names assignees as ClickUp does; shares go to their members.
+	contributors, err := tasksource.SplitCredit(team, emails, primaryAt, field)
+	if err != nil {
+		return err
+	}
+	completedTask.Contributors = tasksource.MergeContributors(contributors, members)
+	return completedTask.NormalizeContributors()
+}
+
+// memberID is the member identifier of a ClickUp assignee, matched on their
+// ClickUp user id or email.
+func memberID(assignee ClickUpAssignee) string {
+	accountID := ""
+	if assignee.ID != 0 {
+		accountID = strconv.FormatInt(assignee.ID, 10)
+	}
+	return tasksource.ResolveAssignee(collectionmodels.SOURCE_CLICKUP, accountID, assignee.Email)
+}
+
+// ProcessWebhookTask converts a task delivered on the task-done webhook.
+func ProcessWebhookTask(task *ClickUpTask) (*collectionmodels.CompletedTask, error) {
+	return processWebhook(syncconfig.WEBHOOK_TASK, task)
+}
+
+// ProcessWebhookConcept converts a task delivered on the concept-done webhook.
+func ProcessWebhookConcept(task *ClickUpTask) (*collectionmodels.CompletedTask, error) {
+	return processWebhook(syncconfig.WEBHOOK_CONCEPT, task)
+}
+
+func processWebhook(webhook string, task *ClickUpTask) (*collectionmodels.CompletedTask, error) {
+	tags := make([]string, 0, len(task.Tags))
+	for _, t := range task.Tags {
+		tags = append(tags, t.Name)
+	}
+	cfg := syncconfig.Get()
+	team, source, ok := cfg.ResolveWebhook(webhook, task.Space.ID, tags)
+	if !ok {
+		return nil, fmt.Errorf("%w: space %s with tags %v for task %s", ErrUntrackedTask, task.Space.ID, tags, task.Id)
+	}
+
+	return normalizeTask(task, team, source)
+}
+
+// taskWeek is the DoneDate a task is stored under (see Config.DoneWeek), taking
+// the current week when ClickUp has no done date.
+func taskWeek(task *ClickUpTask, team *syncconfig.Team, source *syncconfig.Source, cfg *syncconfig.Config) time.Time {
+	doneDate := time.Now()
+	if source.DoneDateFieldID != "" {
+		if ms, ok := customFieldMillis(task, source.DoneDateFieldID); ok && ms > 0 {
+			doneDate = UnixMillisToTime(ms)
+		}
+	} else if task.DateDone != "" {
+		doneDate = UnixMillisToTimeStr(task.DateDone)
+	}
+	return cfg.DoneWeek(team, doneDate)
+}
+
+func customFieldMillis(task *ClickUpTask, fieldID string) (int64, bool) {
+	for _, cf := range task.CustomFields {
+		if cf.ID == fieldID && cf.Value != nil {
+			return anyToInt64(cf.Value)
+		}
+	}
+	return 0, false
+}
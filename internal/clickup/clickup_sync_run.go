@@ -0,0 +1,66 @@
+package clickup
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	database "performance-dashboard-backend/internal/database"
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+)
+
+// syncRun records a sync job in the sync-run collection. Failing to record is
+// logged and never fails the sync itself.
+type syncRun struct {
+	*collectionmodels.SyncRun
+}
+
+func startSyncRun(kind, trigger, team string, from, to time.Time) *syncRun {
+	run := &syncRun{&collectionmodels.SyncRun{
+		Kind:       kind,
+		Trigger:    trigger,
+		Team:       team,
+		WindowFrom: from,
+		WindowTo:   to,
+		StartedAt:  time.Now().UTC(),
+		Status:     collectionmodels.SYNC_STATUS_RUNNING,
+	}}
+	if err := collectionmodels.InsertSyncRun(database.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_SYNC_RUN"), run.SyncRun); err != nil {
+		fmt.Println("Error recording sync run:", err)
+	}
+	return run
+}
+
+func (r *syncRun) addTasks(result *TeamTasks) {
+	r.Fetched += result.Fetched
+	r.Accepted += len(result.Tasks)
+	r.Skipped += result.Skipped
+	r.Rejected += len(result.Rejections)
+	r.Rejections = append(r.Rejections, result.Rejections...)
+}
+
+func (r *syncRun) addError(err error) {
+	r.Errors = append(r.Errors, err.Error())
+}
+
+// finish closes the run: failed when err is set, partial when some steps
+// recorded errors, success otherwise.
+func (r *syncRun) finish(err error) {
+	now := time.Now().UTC()
+	r.FinishedAt = &now
+	switch {
+	case err != nil:
+		r.addError(err)
+		r.Status = collectionmodels.SYNC_STATUS_FAILED
+	case len(r.Errors) > 0:
+		r.Status = collectionmodels.SYNC_STATUS_PARTIAL
+	default:
+		r.Status = collectionmodels.SYNC_STATUS_SUCCESS
+	}
+	if r.ID.IsZero() {
+		return
+	}
+	if err := collectionmodels.UpdateSyncRun(database.GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_SYNC_RUN"), r.SyncRun); err != nil {
+		fmt.Println("Error recording sync run:", err)
+	}
+}
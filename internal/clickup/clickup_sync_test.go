@@ -0,0 +1,46 @@
+package clickup
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+)
+
+func TestProcessWebhookUntracked(t *testing.T) {
+	task := &ClickUpTask{Id: "t1"}
+	task.Space.ID = "no-team-syncs-this"
+	for name, process := range map[string]func(*ClickUpTask) error{
+		"task":    func(t *ClickUpTask) error { _, err := ProcessWebhookTask(t); return err },
+		"concept": func(t *ClickUpTask) error { _, err := ProcessWebhookConcept(t); return err },
+	} {
+		if err := process(task); !errors.Is(err, ErrUntrackedTask) {
+			t.Errorf("%s webhook: error %v, want ErrUntrackedTask", name, err)
+		}
+	}
+}
+
+func TestSplitCreditPrimary(t *testing.T) {
+	team := &syncconfig.Team{Team: "Art", Credit: &syncconfig.Credit{Mode: syncconfig.CREDIT_WEIGHTED, Weights: []float64{60, 30, 10}}}
+	task := &ClickUpTask{Id: "t1", Assignees: []ClickUpAssignee{{ID: 1}, {ID: 2, Email: "b@x.com"}, {ID: 3, Email: "c@x.com"}, {ID: 4, Email: "d@x.com"}}}
+
+	// The primary share goes to the assignee the record is credited to, even
+	// when assignees before it have no email.
+	completed := &collectionmodels.CompletedTask{AssigneeID: "c@x.com"}
+	if err := splitCredit(completed, task, team, 2, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []collectionmodels.Contributor{{AssigneeID: "b@x.com", Share: 0.3}, {AssigneeID: "c@x.com", Share: 0.6}, {AssigneeID: "d@x.com", Share: 0.1}}
+	if completed.AssigneeID != "c@x.com" || !reflect.DeepEqual(completed.Contributors, want) {
+		t.Errorf("credited to %s with %v, want c@x.com with %v", completed.AssigneeID, completed.Contributors, want)
+	}
+
+	// A primary without an email cannot take the primary share; the first
+	// assignee with one must not take it in their place.
+	completed = &collectionmodels.CompletedTask{AssigneeID: "1"}
+	if err := splitCredit(completed, task, team, 0, nil); err == nil {
+		t.Errorf("split with a primary without an email: %v", completed.Contributors)
+	}
+}
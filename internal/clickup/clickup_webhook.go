@@ -0,0 +1,157 @@
+package clickup
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ClickUp webhook event names we care about.
+const (
+	EVENT_TASK_CREATED          = "taskCreated"
+	EVENT_TASK_UPDATED          = "taskUpdated"
+	EVENT_TASK_DELETED          = "taskDeleted"
+	EVENT_TASK_STATUS_UPDATED   = "taskStatusUpdated"
+	EVENT_TASK_ASSIGNEE_UPDATED = "taskAssigneeUpdated"
+	EVENT_TASK_TAG_UPDATED      = "taskTagUpdated"
+)
+
+const defaultWebhookMaxAge = 6 * time.Hour
+
+var (
+	ErrUnknownWebhook   = errors.New("unknown webhook id")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleWebhook     = errors.New("webhook event is too old")
+	ErrUndatedWebhook   = errors.New("webhook event has no history item date")
+	// ErrUntrackedTask is returned for a task in a space, or without a tag, that
+	// no team syncs; shared spaces deliver many of these.
+	ErrUntrackedTask = errors.New("no sync source matches the task")
+)
+
+// WebhookSecrets maps a ClickUp webhook id to the secret ClickUp returned when it was created.
+type WebhookSecrets map[string]string
+
+// LoadWebhookSecretsFromEnv parses CLICKUP_WEBHOOK_SECRETS, formatted as
+// "webhookID=secret,otherWebhookID=otherSecret".
+func LoadWebhookSecretsFromEnv() WebhookSecrets {
+	secrets := WebhookSecrets{}
+	for _, pair := range strings.Split(os.Getenv("CLICKUP_WEBHOOK_SECRETS"), ",") {
+		id, secret, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || id == "" || secret == "" {
+			continue
+		}
+		secrets[strings.TrimSpace(id)] = strings.TrimSpace(secret)
+	}
+	return secrets
+}
+
+// WebhookMaxAge reads CLICKUP_WEBHOOK_MAX_AGE (a Go duration); events older than this are rejected.
+func WebhookMaxAge() time.Duration {
+	if v := os.Getenv("CLICKUP_WEBHOOK_MAX_AGE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		fmt.Println("Invalid CLICKUP_WEBHOOK_MAX_AGE, using default:", v)
+	}
+	return defaultWebhookMaxAge
+}
+
+// VerifyWebhookSignature checks the X-Signature header: hex(HMAC-SHA256(secret, raw body)).
+func VerifyWebhookSignature(body []byte, signature, secret string) bool {
+	expected, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// ParseVerifiedWebhook parses the raw body and authenticates it against the secret
+// registered for its webhook_id, then rejects events older than maxAge. An event
+// without a date cannot be checked for age, so it is rejected too: a replay of
+// it would otherwise be accepted again once its dedupe entry expired. The
+// weekly sync still picks up whatever such an event changed.
+func ParseVerifiedWebhook(body []byte, signature string, secrets WebhookSecrets, maxAge time.Duration) (*ClickUpWebhookPayload, error) {
+	var payload ClickUpWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	secret, ok := secrets[payload.WebhookID]
+	if !ok {
+		return nil, ErrUnknownWebhook
+	}
+	if !VerifyWebhookSignature(body, signature, secret) {
+		return nil, ErrInvalidSignature
+	}
+	if payload.TaskID == "" {
+		return nil, errors.New("webhook payload has no task_id")
+	}
+	occurredAt := payload.OccurredAt()
+	if occurredAt.IsZero() {
+		return nil, ErrUndatedWebhook
+	}
+	if time.Since(occurredAt) > maxAge {
+		return nil, ErrStaleWebhook
+	}
+	payload.body = body
+	return &payload, nil
+}
+
+// EventID identifies an event for replay protection. ClickUp history item ids are
+// unique per change; when there are none we fall back to a hash of the event
+// fields and the body, so later events of the same kind for the task are not
+// taken for replays.
+func (p *ClickUpWebhookPayload) EventID() string {
+	var ids []string
+	for _, item := range p.HistoryItems {
+		if item.ID != "" {
+			ids = append(ids, item.ID)
+		}
+	}
+	if len(ids) > 0 {
+		sort.Strings(ids)
+		return p.WebhookID + ":" + strings.Join(ids, ",")
+	}
+	h := sha256.New()
+	h.Write([]byte(p.WebhookID + "|" + p.Event + "|" + p.TaskID + "|"))
+	h.Write(p.body)
+	return p.WebhookID + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// OccurredAt is the latest history item date, or zero when ClickUp sent none.
+func (p *ClickUpWebhookPayload) OccurredAt() time.Time {
+	var latest time.Time
+	for _, item := range p.HistoryItems {
+		if item.Date == "" {
+			continue
+		}
+		if t := UnixMillisToTimeStr(item.Date); t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// IsTaskDone reports whether a regular team task is finished in ClickUp.
+func IsTaskDone(task *ClickUpTask) bool {
+	statusType := strings.ToLower(task.Status.Type)
+	return statusType == "closed" || statusType == "done" || task.DateDone != ""
+}
+
+// IsConceptDone reports whether a concept task carries the concept-done tag.
+func IsConceptDone(task *ClickUpTask) bool {
+	for _, t := range task.Tags {
+		if strings.EqualFold(strings.TrimSpace(t.Name), TAG_CONCEPT_DONE) {
+			return true
+		}
+	}
+	return false
+}
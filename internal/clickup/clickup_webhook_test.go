@@ -0,0 +1,103 @@
+package clickup
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testSecret = "s3cret"
+
+var testSecrets = WebhookSecrets{"wh1": testSecret}
+
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// delivery builds a webhook body for task with one history item per date
+// (ids left empty when ids is nil).
+func delivery(t *testing.T, event, task string, ids []string, dates ...time.Time) []byte {
+	t.Helper()
+	var items []map[string]interface{}
+	for i, d := range dates {
+		item := map[string]interface{}{"field": "status", "date": strconv.FormatInt(d.UnixMilli(), 10)}
+		if ids != nil {
+			item["id"] = ids[i]
+		}
+		items = append(items, item)
+	}
+	body, err := json.Marshal(map[string]interface{}{"event": event, "task_id": task, "webhook_id": "wh1", "history_items": items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestParseVerifiedWebhook(t *testing.T) {
+	now := time.Now()
+	fresh := delivery(t, EVENT_TASK_STATUS_UPDATED, "t1", nil, now.Add(-time.Minute))
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+		secrets   WebhookSecrets
+		wantErr   error
+	}{
+		{name: "valid", body: fresh, signature: sign(fresh), secrets: testSecrets},
+		{name: "bad signature", body: fresh, signature: sign([]byte("other")), secrets: testSecrets, wantErr: ErrInvalidSignature},
+		{name: "unknown webhook", body: fresh, signature: sign(fresh), secrets: WebhookSecrets{"wh2": testSecret}, wantErr: ErrUnknownWebhook},
+		{name: "stale", body: delivery(t, EVENT_TASK_STATUS_UPDATED, "t1", nil, now.Add(-7*time.Hour)), secrets: testSecrets, wantErr: ErrStaleWebhook},
+		{name: "no history items", body: delivery(t, EVENT_TASK_STATUS_UPDATED, "t1", nil), secrets: testSecrets, wantErr: ErrUndatedWebhook},
+		{name: "unreadable date", body: []byte(`{"event":"taskStatusUpdated","task_id":"t1","webhook_id":"wh1","history_items":[{"date":"soon"}]}`), secrets: testSecrets, wantErr: ErrStaleWebhook},
+	}
+	for _, tt := range tests {
+		signature := tt.signature
+		if signature == "" {
+			signature = sign(tt.body)
+		}
+		payload, err := ParseVerifiedWebhook(tt.body, signature, tt.secrets, 6*time.Hour)
+		if tt.wantErr == nil {
+			if err != nil || payload.TaskID != "t1" {
+				t.Errorf("%s: payload %+v, error %v", tt.name, payload, err)
+			}
+			continue
+		}
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEventID(t *testing.T) {
+	now := time.Now()
+	parse := func(body []byte) *ClickUpWebhookPayload {
+		t.Helper()
+		payload, err := ParseVerifiedWebhook(body, sign(body), testSecrets, 6*time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return payload
+	}
+
+	first := delivery(t, EVENT_TASK_STATUS_UPDATED, "t1", nil, now.Add(-2*time.Minute))
+	later := delivery(t, EVENT_TASK_STATUS_UPDATED, "t1", nil, now.Add(-time.Minute))
+	if parse(first).EventID() != parse(first).EventID() {
+		t.Error("a redelivered event got a new id")
+	}
+	if parse(first).EventID() == parse(later).EventID() {
+		t.Error("a later event of the same kind for the task was taken for a replay")
+	}
+
+	withIDs := delivery(t, EVENT_TASK_STATUS_UPDATED, "t1", []string{"h2", "h1"}, now.Add(-time.Minute), now.Add(-time.Minute))
+	if got := parse(withIDs).EventID(); got != "wh1:h1,h2" {
+		t.Errorf("event id %q, want the sorted history item ids", got)
+	}
+}
@@ -0,0 +1,219 @@
+package collectionmodels
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	AUDIT_ENTITY_MEMBER            = "member"
+	AUDIT_ENTITY_PROJECT_DETAIL    = "project_detail"
+	AUDIT_ENTITY_CREATIVE_TOOL     = "creative_tool"
+	AUDIT_ENTITY_LEVEL             = "level"
+	AUDIT_ENTITY_WEEKLY_TARGET     = "weekly_target"
+	AUDIT_ENTITY_WEEKLY_ORDER      = "weekly_order"
+	AUDIT_ENTITY_TEMP_WEEKLY_ORDER = "temp_weekly_order"
+	AUDIT_ENTITY_PROJECT_ISSUE     = "project_issue"
+	AUDIT_ENTITY_COMPLETED_TASK    = "completed_task"
+)
+
+// ErrAuditConflict is returned when the audited documents changed after the
+// entry was recorded, so restoring its before state would lose that change.
+var ErrAuditConflict = errors.New("documents changed since the audit entry was recorded")
+
+// AuditEntry is one administrative write: the raw documents of Collection
+// matched by the entity before and after it, so it can be reviewed and reverted.
+type AuditEntry struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Actor      string             `bson:"actor" json:"actor"`
+	Endpoint   string             `bson:"endpoint" json:"endpoint"`
+	Entity     string             `bson:"entity" json:"entity"`
+	EntityKey  string             `bson:"entity_key" json:"entity_key"`
+	Collection string             `bson:"collection" json:"collection"`
+	Before     []bson.M           `bson:"before" json:"before"`
+	After      []bson.M           `bson:"after" json:"after"`
+	At         time.Time          `bson:"at" json:"at"`
+	// RevertOf is the entry a revert undid; RevertedBy the revert that undid this one.
+	RevertOf   *primitive.ObjectID `bson:"revert_of,omitempty" json:"revert_of,omitempty"`
+	RevertedBy *primitive.ObjectID `bson:"reverted_by,omitempty" json:"reverted_by,omitempty"`
+}
+
+func InsertAuditEntry(client *mongo.Client, dbName, collectionName string, entry *AuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	res, err := collection.InsertOne(ctx, entry)
+	if err != nil {
+		return err
+	}
+	entry.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func GetAuditEntryByID(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) (*AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	var entry AuditEntry
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetAuditEntries lists entries, newest first, filtered by entity, entity key
+// and actor when set.
+func GetAuditEntries(client *mongo.Client, dbName, collectionName, entity, entityKey, actor string, limit int64) ([]AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+
+	filter := bson.M{}
+	if entity != "" {
+		filter["entity"] = entity
+	}
+	if entityKey != "" {
+		filter["entity_key"] = entityKey
+	}
+	if actor != "" {
+		filter["actor"] = actor
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "at", Value: -1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ClaimAuditRevert marks an entry as reverted by revertID. It reports false when
+// the entry was already reverted.
+func ClaimAuditRevert(client *mongo.Client, dbName, collectionName string, id, revertID primitive.ObjectID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	res, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "reverted_by": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"reverted_by": revertID}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// ReleaseAuditRevert undoes ClaimAuditRevert after the revert could not be applied.
+func ReleaseAuditRevert(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.UpdateByID(ctx, id, bson.M{"$unset": bson.M{"reverted_by": ""}})
+	return err
+}
+
+// SnapshotDocuments returns the raw documents of a collection matching filter,
+// or having one of ids.
+func SnapshotDocuments(client *mongo.Client, dbName, collectionName string, filter bson.M, ids []interface{}) ([]bson.M, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+
+	query := filter
+	if len(ids) > 0 {
+		query = bson.M{"$or": bson.A{filter, bson.M{"_id": bson.M{"$in": ids}}}}
+	}
+	cursor, err := collection.Find(ctx, query, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	docs := []bson.M{}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// DocumentIDs returns the _id of each document.
+func DocumentIDs(docs ...[]bson.M) []interface{} {
+	seen := map[interface{}]bool{}
+	ids := []interface{}{}
+	for _, set := range docs {
+		for _, doc := range set {
+			if id, ok := doc["_id"]; ok && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// RestoreDocuments puts the documents touched by an audit entry back to its
+// before state: documents it created are deleted and the others replaced with
+// their earlier version. It returns ErrAuditConflict, writing nothing, when the
+// documents no longer match the entry's after state.
+func RestoreDocuments(client *mongo.Client, dbName, collectionName string, entry *AuditEntry) error {
+	ids := DocumentIDs(entry.Before, entry.After)
+	if len(ids) == 0 {
+		return nil
+	}
+	current, err := SnapshotDocuments(client, dbName, collectionName, bson.M{"_id": bson.M{"$in": ids}}, nil)
+	if err != nil {
+		return err
+	}
+	if !sameDocuments(current, entry.After) {
+		return ErrAuditConflict
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	kept := map[interface{}]bool{}
+	for _, doc := range entry.Before {
+		kept[doc["_id"]] = true
+		if _, err := collection.ReplaceOne(ctx, bson.M{"_id": doc["_id"]}, doc, options.Replace().SetUpsert(true)); err != nil {
+			return err
+		}
+	}
+	for _, doc := range entry.After {
+		if kept[doc["_id"]] {
+			continue
+		}
+		if _, err := collection.DeleteOne(ctx, bson.M{"_id": doc["_id"]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sameDocuments(a, b []bson.M) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	byID := map[interface{}]bson.M{}
+	for _, doc := range b {
+		byID[doc["_id"]] = doc
+	}
+	for _, doc := range a {
+		other, ok := byID[doc["_id"]]
+		if !ok || !reflect.DeepEqual(doc, other) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+package collectionmodels
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	CHANGE_REQUEST_PENDING  = "pending"
+	CHANGE_REQUEST_APPROVED = "approved"
+	CHANGE_REQUEST_REJECTED = "rejected"
+
+	CHANGE_ACTION_SUBMITTED = "submitted"
+	CHANGE_ACTION_APPROVED  = "approved"
+	CHANGE_ACTION_REJECTED  = "rejected"
+	// CHANGE_ACTION_FAILED is an approval whose write failed; the request is pending again.
+	CHANGE_ACTION_FAILED = "failed"
+)
+
+// ChangeRequest is a manual addition or edit of a completed task waiting for the
+// team manager. Only approved requests are written to the completed-task collection.
+type ChangeRequest struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TaskID string             `bson:"task_id" json:"task_id"`
+	Team   string             `bson:"team" json:"team"`
+	// Proposed is the record as requested; Current the stored record it would
+	// replace when the request was made, nil for an addition.
+	Proposed  CompletedTask           `bson:"proposed" json:"proposed"`
+	Current   *CompletedTask          `bson:"current,omitempty" json:"current,omitempty"`
+	Reason    string                  `bson:"reason" json:"reason"`
+	Requester string                  `bson:"requester" json:"requester"`
+	Status    string                  `bson:"status" json:"status"`
+	CreatedAt time.Time               `bson:"created_at" json:"created_at"`
+	DecidedAt *time.Time              `bson:"decided_at,omitempty" json:"decided_at,omitempty"`
+	DecidedBy string                  `bson:"decided_by,omitempty" json:"decided_by,omitempty"`
+	Trail     []ChangeRequestDecision `bson:"trail" json:"trail"`
+}
+
+// ChangeRequestDecision is one step of a request's history.
+type ChangeRequestDecision struct {
+	Action  string    `bson:"action" json:"action"`
+	Actor   string    `bson:"actor" json:"actor"`
+	Comment string    `bson:"comment,omitempty" json:"comment,omitempty"`
+	At      time.Time `bson:"at" json:"at"`
+}
+
+func InsertChangeRequest(client *mongo.Client, dbName, collectionName string, request *ChangeRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	res, err := collection.InsertOne(ctx, request)
+	if err != nil {
+		return err
+	}
+	request.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func GetChangeRequestByID(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) (*ChangeRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	var request ChangeRequest
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetChangeRequests lists requests, newest first, filtered by teams (all when
+// empty; both the proposed team and that of the replaced record count), status
+// and requester when set.
+func GetChangeRequests(client *mongo.Client, dbName, collectionName string, teams []string, status, requester string, limit int64) ([]ChangeRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+
+	filter := bson.M{}
+	if len(teams) > 0 {
+		// A request moving a record out of a team concerns that team too.
+		filter["$or"] = bson.A{
+			bson.M{"team": bson.M{"$in": teams}},
+			bson.M{"current.team": bson.M{"$in": teams}},
+		}
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+	if requester != "" {
+		filter["requester"] = requester
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	requests := []ChangeRequest{}
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// DecideChangeRequest moves a pending request to status and appends the decision.
+// It reports false when the request was no longer pending.
+func DecideChangeRequest(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, status string, decision ChangeRequestDecision) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	res, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": CHANGE_REQUEST_PENDING},
+		bson.M{
+			"$set":  bson.M{"status": status, "decided_at": decision.At, "decided_by": decision.Actor},
+			"$push": bson.M{"trail": decision},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// ReopenChangeRequest puts a request back to pending after its approval could
+// not be applied, keeping the failure in the trail.
+func ReopenChangeRequest(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, decision ChangeRequestDecision) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.UpdateByID(ctx, id, bson.M{
+		"$set":   bson.M{"status": CHANGE_REQUEST_PENDING},
+		"$unset": bson.M{"decided_at": "", "decided_by": ""},
+		"$push":  bson.M{"trail": decision},
+	})
+	return err
+}
@@ -1,10 +1,16 @@
 package collectionmodels
 
 import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
 	"context"
 
+	"performance-dashboard-backend/internal/database/constants"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -22,6 +28,164 @@ type CompletedTask struct {
 	Project    string             `bson:"project"`
 	Team       string             `bson:"team"`
 	DoneDate   time.Time          `bson:"done_date"`
+	// Contributors share the points of a task done by several assignees; AssigneeID
+	// is the primary one and is listed too. Empty means AssigneeID earns the whole task.
+	Contributors []Contributor `bson:"contributors,omitempty"`
+	// Source is the tracker the record came from; records from before sources
+	// were tracked have none and count as ClickUp.
+	Source string `bson:"source,omitempty"`
+
+	// A voided record is kept for history but no longer earns points, e.g. after
+	// the task was reopened, deleted or handed to someone else in ClickUp.
+	Void       bool       `bson:"void,omitempty"`
+	VoidReason string     `bson:"void_reason,omitempty"`
+	VoidedAt   *time.Time `bson:"voided_at,omitempty"`
+}
+
+const (
+	VOID_REASON_REOPENED   = "reopened"
+	VOID_REASON_DELETED    = "deleted"
+	VOID_REASON_REASSIGNED = "reassigned"
+	// VOID_REASON_SUPERSEDED marks the losing side of a conflict between sources.
+	VOID_REASON_SUPERSEDED = "superseded"
+	// VOID_REASON_UNTRACKED marks a task moved to a space or tag no team syncs.
+	VOID_REASON_UNTRACKED = "untracked"
+
+	SOURCE_CLICKUP = "clickup"
+	SOURCE_ASANA   = "asana"
+	SOURCE_MANUAL  = "manual"
+)
+
+type Contributor struct {
+	AssigneeID string  `bson:"assignee_id"`
+	Share      float64 `bson:"share"`
+}
+
+// CreditShare is the part of the task's points earned by assigneeID. An
+// identifier that is not a contributor, such as a team, gets the whole task.
+func (t *CompletedTask) CreditShare(assigneeID string) float64 {
+	for _, c := range t.Contributors {
+		if c.AssigneeID == assigneeID {
+			return c.Share
+		}
+	}
+	return 1
+}
+
+// NormalizeContributors drops contributors without a share and scales the rest
+// to add up to 1. AssigneeID falls back to the first contributor when it has no
+// share, and a single remaining contributor becomes the plain AssigneeID.
+func (t *CompletedTask) NormalizeContributors() error {
+	if len(t.Contributors) == 0 {
+		return nil
+	}
+	total := 0.0
+	seen := make(map[string]bool, len(t.Contributors))
+	kept := make([]Contributor, 0, len(t.Contributors))
+	for _, c := range t.Contributors {
+		if c.AssigneeID == "" || seen[c.AssigneeID] {
+			return fmt.Errorf("contributors must be distinct and non-empty")
+		}
+		seen[c.AssigneeID] = true
+		if c.Share < 0 || math.IsNaN(c.Share) || math.IsInf(c.Share, 0) {
+			return fmt.Errorf("invalid share %v for %s", c.Share, c.AssigneeID)
+		}
+		if c.Share == 0 {
+			continue
+		}
+		total += c.Share
+		kept = append(kept, c)
+	}
+	if total == 0 {
+		return fmt.Errorf("contributors have no share")
+	}
+	if !slices.ContainsFunc(kept, func(c Contributor) bool { return c.AssigneeID == t.AssigneeID }) {
+		t.AssigneeID = kept[0].AssigneeID
+	}
+	if len(kept) == 1 {
+		t.AssigneeID, t.Contributors = kept[0].AssigneeID, nil
+		return nil
+	}
+	for i := range kept {
+		kept[i].Share /= total
+	}
+	t.Contributors = kept
+	return nil
+}
+
+// RecordSource is the source of a record, ClickUp for records stored before sources were tracked.
+func (t *CompletedTask) RecordSource() string {
+	if t.Source == "" {
+		return SOURCE_CLICKUP
+	}
+	return t.Source
+}
+
+// IsConcept reports whether t is a concept record rather than a team record.
+func (t *CompletedTask) IsConcept() bool {
+	return t.Team == constants.Concept
+}
+
+// conceptFilter matches the team of concept records, or of team records.
+func conceptFilter(concept bool) interface{} {
+	if concept {
+		return constants.Concept
+	}
+	return bson.M{"$ne": constants.Concept}
+}
+
+// recordFilter matches the record of a task credited to assigneeID. Concept
+// records share the task id with the team record of the same task, so the two
+// are told apart by team.
+func recordFilter(taskID, assigneeID string, concept bool) bson.M {
+	return bson.M{"id": taskID, "assignee_id": assigneeID, "team": conceptFilter(concept)}
+}
+
+// SourceFilter matches the records of a source, including untagged records for ClickUp.
+func SourceFilter(source string) interface{} {
+	if source == SOURCE_CLICKUP {
+		return bson.M{"$in": bson.A{SOURCE_CLICKUP, nil}}
+	}
+	return source
+}
+
+// ChangedFields lists the stored fields (by bson name) that differ between two
+// records of the same task.
+func ChangedFields(before, after *CompletedTask) []string {
+	var fields []string
+	if before.TaskName != after.TaskName {
+		fields = append(fields, "task_name")
+	}
+	if before.AssigneeID != after.AssigneeID {
+		fields = append(fields, "assignee_id")
+	}
+	if !slices.Equal(before.Contributors, after.Contributors) {
+		fields = append(fields, "contributors")
+	}
+	if !slices.Equal(before.Tool, after.Tool) {
+		fields = append(fields, "tool")
+	}
+	if before.Level != after.Level {
+		fields = append(fields, "level")
+	}
+	if before.TaskType != after.TaskType {
+		fields = append(fields, "task_type")
+	}
+	if before.Project != after.Project {
+		fields = append(fields, "project")
+	}
+	if before.Team != after.Team {
+		fields = append(fields, "team")
+	}
+	if !before.DoneDate.Equal(after.DoneDate) {
+		fields = append(fields, "done_date")
+	}
+	return fields
+}
+
+// NotVoided matches completed tasks that still count towards performance.
+func NotVoided() bson.E {
+	return bson.E{Key: "void", Value: bson.M{"$ne": true}}
 }
 
 //	{
@@ -38,7 +202,7 @@ func UpsertCompletedTask(client *mongo.Client, dbName, collectionName string, ta
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{"id": task.TaskID, "assignee_id": task.AssigneeID}
+	filter := recordFilter(task.TaskID, task.AssigneeID, task.IsConcept())
 	var update bson.M
 	if allowUpdate {
 		update = bson.M{"$set": task}
@@ -50,6 +214,59 @@ func UpsertCompletedTask(client *mongo.Client, dbName, collectionName string, ta
 	return err
 }
 
+// UpsertCompletedTasks inserts the tasks that are not stored yet (by task id,
+// assignee and whether they are concept records) and leaves existing records untouched, so re-running an import is safe.
+func UpsertCompletedTasks(client *mongo.Client, dbName, collectionName string, tasks []*CompletedTask) (inserted, existing int64, err error) {
+	if len(tasks) == 0 {
+		return 0, 0, nil
+	}
+	collection := client.Database(dbName).Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	models := make([]mongo.WriteModel, 0, len(tasks))
+	for _, task := range tasks {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(recordFilter(task.TaskID, task.AssigneeID, task.IsConcept())).
+			SetUpdate(bson.M{"$setOnInsert": task}).
+			SetUpsert(true))
+	}
+	res, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, 0, err
+	}
+	return res.UpsertedCount, res.MatchedCount, nil
+}
+
+// DedupeCompletedTasksByName keeps the first task of each name (case-insensitive),
+// for sources where the same work is tracked as several tasks.
+func DedupeCompletedTasksByName(tasks []*CompletedTask) []*CompletedTask {
+	if len(tasks) == 0 {
+		return tasks
+	}
+
+	seen := make(map[string]struct{}, len(tasks))
+	out := make([]*CompletedTask, 0, len(tasks))
+
+	for _, task := range tasks {
+		if task == nil {
+			continue
+		}
+		nameKey := strings.ToLower(strings.TrimSpace(task.TaskName))
+		if nameKey == "" {
+			out = append(out, task)
+			continue
+		}
+		if _, ok := seen[nameKey]; ok {
+			continue
+		}
+		seen[nameKey] = struct{}{}
+		out = append(out, task)
+	}
+
+	return out
+}
+
 func InsertCompletedTaskToDataBase(client *mongo.Client, dbName, collectionName string, tasks []*CompletedTask) error {
 	collection := client.Database(dbName).Collection(collectionName)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -71,6 +288,7 @@ func GetProjectsWithCompletedTasks(client *mongo.Client, dbName, collectionName
 				{Key: "$gte", Value: startDate},
 				{Key: "$lte", Value: endDate},
 			}},
+			NotVoided(),
 		}}},
 		{{Key: "$group", Value: bson.D{
 			{Key: "_id", Value: "$project"},
@@ -108,19 +326,21 @@ func GetProjectsWithCompletedTasks(client *mongo.Client, dbName, collectionName
 func GetCompletedTasksByDateRange(client *mongo.Client, dbName, collectionName string, isTeam bool, identifier string, startDate, endDate time.Time) ([]CompletedTask, error) {
 	collection := client.Database(dbName).Collection(collectionName)
 
-	var indentifierKey string
-	if isTeam {
-		indentifierKey = "team"
-	} else {
-		indentifierKey = "assignee_id"
-	}
-
 	filter := bson.M{
-		indentifierKey: identifier,
 		"done_date": bson.M{
 			"$gte": startDate,
 			"$lte": endDate,
 		},
+		"void": bson.M{"$ne": true},
+	}
+	if isTeam {
+		filter["team"] = identifier
+	} else {
+		// A member also gets their share of the tasks they contributed to.
+		filter["$or"] = bson.A{
+			bson.M{"assignee_id": identifier},
+			bson.M{"contributors.assignee_id": identifier},
+		}
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -145,3 +365,245 @@ func GetCompletedTasksByDateRange(client *mongo.Client, dbName, collectionName s
 	}
 	return tasks, nil
 }
+
+// GetCompletedTasksByTaskID returns every record a source holds for a task, voided ones included.
+// Concept records share the task id with the team record of the same task, so the
+// two are looked up separately.
+func GetCompletedTasksByTaskID(client *mongo.Client, dbName, collectionName, source, taskID string, concept bool) ([]CompletedTask, error) {
+	collection := client.Database(dbName).Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id": taskID, "source": SourceFilter(source), "team": conceptFilter(concept)}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []CompletedTask
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// GetCompletedTask returns the concept or team record of a task credited to
+// assigneeID, voided or not, or nil.
+func GetCompletedTask(client *mongo.Client, dbName, collectionName, taskID, assigneeID string, concept bool) (*CompletedTask, error) {
+	collection := client.Database(dbName).Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var task CompletedTask
+	err := collection.FindOne(ctx, recordFilter(taskID, assigneeID, concept)).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetCompletedTaskByID returns a record by its _id, voided or not, or nil.
+func GetCompletedTaskByID(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) (*CompletedTask, error) {
+	collection := client.Database(dbName).Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var task CompletedTask
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ReplaceCompletedTask overwrites the scored fields of a record and clears any void flag.
+func ReplaceCompletedTask(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, task *CompletedTask) error {
+	collection := client.Database(dbName).Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	set := bson.M{
+		"task_name":   task.TaskName,
+		"assignee_id": task.AssigneeID,
+		"tool":        task.Tool,
+		"level":       task.Level,
+		"task_type":   task.TaskType,
+		"project":     task.Project,
+		"team":        task.Team,
+		"done_date":   task.DoneDate,
+		"source":      task.RecordSource(),
+	}
+	unset := bson.M{"void": "", "void_reason": "", "voided_at": ""}
+	if len(task.Contributors) > 0 {
+		set["contributors"] = task.Contributors
+	} else {
+		unset["contributors"] = ""
+	}
+	update := bson.M{"$set": set, "$unset": unset}
+	_, err := collection.UpdateByID(ctx, id, update)
+	return err
+}
+
+func VoidCompletedTask(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, reason string, at time.Time) error {
+	collection := client.Database(dbName).Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"void": true, "void_reason": reason, "voided_at": at}}
+	_, err := collection.UpdateByID(ctx, id, update)
+	return err
+}
+
+// GetLiveCompletedTasks returns the records of a task that still earn points, from every source.
+func GetLiveCompletedTasks(client *mongo.Client, dbName, collectionName, taskID string) ([]CompletedTask, error) {
+	collection := client.Database(dbName).Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, bson.D{{Key: "id", Value: taskID}, NotVoided()})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []CompletedTask
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// GetCompletedTasksWithUnknownAssignees returns the live records done in
+// [startDate, endDate] whose assignee or a contributor is not in known.
+func GetCompletedTasksWithUnknownAssignees(client *mongo.Client, dbName, collectionName string, known []string, startDate, endDate time.Time) ([]CompletedTask, error) {
+	collection := client.Database(dbName).Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "done_date", Value: bson.M{"$gte": startDate, "$lte": endDate}},
+		NotVoided(),
+		{Key: "$or", Value: bson.A{
+			bson.M{"assignee_id": bson.M{"$nin": known}},
+			bson.M{"contributors": bson.M{"$elemMatch": bson.M{"assignee_id": bson.M{"$nin": known}}}},
+		}},
+	}
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []CompletedTask
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// GetCompletedTasksByAssignee returns every record, voided ones included, where
+// assigneeID is the assignee or a contributor.
+func GetCompletedTasksByAssignee(client *mongo.Client, dbName, collectionName, assigneeID string) ([]CompletedTask, error) {
+	collection := client.Database(dbName).Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"assignee_id": assigneeID},
+		bson.M{"contributors.assignee_id": assigneeID},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []CompletedTask
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// SourceConflict is a task with live records from more than one source.
+type SourceConflict struct {
+	TaskID  string          `bson:"_id" json:"task_id"`
+	Sources []string        `bson:"sources" json:"sources"`
+	Records []CompletedTask `bson:"records" json:"records"`
+}
+
+func GetSourceConflicts(client *mongo.Client, dbName, collectionName string, limit int64) ([]SourceConflict, error) {
+	collection := client.Database(dbName).Collection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{NotVoided()}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$id"},
+			{Key: "sources", Value: bson.D{{Key: "$addToSet", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$source", SOURCE_CLICKUP}}}}}},
+			{Key: "records", Value: bson.D{{Key: "$push", Value: "$$ROOT"}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "sources.1", Value: bson.D{{Key: "$exists", Value: true}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var conflicts []SourceConflict
+	if err := cursor.All(ctx, &conflicts); err != nil {
+		return nil, err
+	}
+	return conflicts, nil
+}
+
+// RelabelWorkWeekDoneDates moves tracker records filed under the Wed → Tue work
+// week rule, Monday 00:00 UTC of that week, to the DoneDate of the sync window
+// they belong to: Monday 09:00 in loc one week later. Manual entries are left
+// alone. It returns how many records were (or, with dryRun, would be) moved.
+func RelabelWorkWeekDoneDates(client *mongo.Client, dbName, collectionName string, loc *time.Location, dryRun bool) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+
+	filter := bson.M{"source": bson.M{"$ne": SOURCE_MANUAL}}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"done_date": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var moved int64
+	for cursor.Next(ctx) {
+		var row struct {
+			ID       primitive.ObjectID `bson:"_id"`
+			DoneDate time.Time          `bson:"done_date"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return moved, err
+		}
+		d := row.DoneDate.UTC()
+		if d.Weekday() != time.Monday || !d.Equal(d.Truncate(24*time.Hour)) {
+			continue
+		}
+		relabeled := time.Date(d.Year(), d.Month(), d.Day()+7, 9, 0, 0, 0, loc)
+		if !dryRun {
+			if _, err := collection.UpdateByID(ctx, row.ID, bson.M{"$set": bson.M{"done_date": relabeled}}); err != nil {
+				return moved, err
+			}
+		}
+		moved++
+	}
+	return moved, cursor.Err()
+}
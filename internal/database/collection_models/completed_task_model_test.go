@@ -0,0 +1,64 @@
+package collectionmodels
+
+import (
+	"reflect"
+	"testing"
+
+	"performance-dashboard-backend/internal/database/constants"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestRecordFilter(t *testing.T) {
+	concept := &CompletedTask{TaskID: "t1", AssigneeID: "a@example.com", Team: constants.Concept}
+	team := &CompletedTask{TaskID: "t1", AssigneeID: "a@example.com", Team: "Art"}
+
+	want := bson.M{"id": "t1", "assignee_id": "a@example.com", "team": constants.Concept}
+	if got := recordFilter(concept.TaskID, concept.AssigneeID, concept.IsConcept()); !reflect.DeepEqual(got, want) {
+		t.Errorf("concept record filter %v, want %v", got, want)
+	}
+	want = bson.M{"id": "t1", "assignee_id": "a@example.com", "team": bson.M{"$ne": constants.Concept}}
+	if got := recordFilter(team.TaskID, team.AssigneeID, team.IsConcept()); !reflect.DeepEqual(got, want) {
+		t.Errorf("team record filter %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeContributors(t *testing.T) {
+	c := func(id string, share float64) Contributor { return Contributor{AssigneeID: id, Share: share} }
+
+	tests := []struct {
+		name         string
+		assignee     string
+		contributors []Contributor
+		wantAssignee string
+		want         []Contributor
+		wantErr      bool
+	}{
+		{name: "no contributors", assignee: "a", wantAssignee: "a"},
+		{name: "scaled to one", assignee: "a", contributors: []Contributor{c("a", 3), c("b", 1)}, wantAssignee: "a", want: []Contributor{c("a", 0.75), c("b", 0.25)}},
+		{name: "zero shares dropped", assignee: "a", contributors: []Contributor{c("a", 1), c("b", 0), c("c", 1)}, wantAssignee: "a", want: []Contributor{c("a", 0.5), c("c", 0.5)}},
+		{name: "primary without a share", assignee: "a", contributors: []Contributor{c("a", 0), c("b", 1), c("c", 1)}, wantAssignee: "b", want: []Contributor{c("b", 0.5), c("c", 0.5)}},
+		{name: "single remaining contributor", assignee: "a", contributors: []Contributor{c("a", 0), c("b", 2)}, wantAssignee: "b"},
+		{name: "duplicate contributor", assignee: "a", contributors: []Contributor{c("a", 1), c("a", 1)}, wantErr: true},
+		{name: "contributor without an id", assignee: "a", contributors: []Contributor{c("a", 1), c("", 1)}, wantErr: true},
+		{name: "negative share", assignee: "a", contributors: []Contributor{c("a", 2), c("b", -1)}, wantErr: true},
+		{name: "no share at all", assignee: "a", contributors: []Contributor{c("a", 0), c("b", 0)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		task := &CompletedTask{AssigneeID: tt.assignee, Contributors: tt.contributors}
+		err := task.NormalizeContributors()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: no error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if task.AssigneeID != tt.wantAssignee || !reflect.DeepEqual(task.Contributors, tt.want) {
+			t.Errorf("%s: assignee %s contributors %v, want %s %v", tt.name, task.AssigneeID, task.Contributors, tt.wantAssignee, tt.want)
+		}
+	}
+}
@@ -16,47 +16,69 @@ type CreativeTool struct {
 	Type     string             `bson:"type"`
 	Point    []float64          `bson:"point"`
 	Index    int                `bson:"index"`
+	// A tool's points are versioned like level tables.
+	Effective `bson:",inline"`
 }
 
+// GetCreativeToolByTeam returns a tool of the team currently in force.
 func GetCreativeToolByTeam(client *mongo.Client, dbName, collectionName, team string) (*CreativeTool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
 	var tool CreativeTool
-	err := collection.FindOne(ctx, bson.M{"team": team}).Decode(&tool)
+	err := collection.FindOne(ctx, currentVersion(bson.M{"team": team})).Decode(&tool)
 	if err != nil {
 		return nil, err
 	}
 	return &tool, nil
 }
 
+// AddCreativeTool adds a tool the team has none of in force (see addVersion); a
+// current tool is changed with UpdateCreativeTool.
 func AddCreativeTool(client *mongo.Client, dbName, collectionName string, tool *CreativeTool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.InsertOne(ctx, tool)
-	return err
+	tool.EffectiveTo = nil
+	return addVersion(ctx, collection, bson.M{"team": tool.Team, "tool_name": tool.ToolName}, &tool.Effective, tool)
 }
 
+// UpdateCreativeTool starts a new version of a tool's type and points at
+// tool.EffectiveFrom (now when unset); the index of the current version is kept.
 func UpdateCreativeTool(client *mongo.Client, dbName, collectionName string, tool *CreativeTool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.UpdateOne(ctx,
-		bson.M{"team": tool.Team, "tool_name": tool.ToolName},
-		bson.M{"$set": bson.M{"type": tool.Type, "point": tool.Point}},
-	)
-	return err
+	filter := bson.M{"team": tool.Team, "tool_name": tool.ToolName}
+
+	var current CreativeTool
+	if err := collection.FindOne(ctx, currentVersion(filter)).Decode(&current); err != nil {
+		return err
+	}
+	from := time.Now().UTC()
+	if tool.EffectiveFrom != nil {
+		from = *tool.EffectiveFrom
+	}
+	next := &CreativeTool{
+		Team:      tool.Team,
+		ToolName:  tool.ToolName,
+		Type:      tool.Type,
+		Point:     tool.Point,
+		Index:     current.Index,
+		Effective: Effective{EffectiveFrom: &from},
+	}
+	return startVersion(ctx, collection, filter, from, next)
 }
 
+// DeleteCreativeTool retires a tool; weeks already scored with it keep it.
 func DeleteCreativeTool(client *mongo.Client, dbName, collectionName, team, toolName string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.DeleteOne(ctx, bson.M{"team": team, "tool_name": toolName})
-	return err
+	return endVersion(ctx, collection, bson.M{"team": team, "tool_name": toolName}, time.Now().UTC())
 }
 
+// GetAllCreativeTools returns every version of every tool.
 func GetAllCreativeTools(client *mongo.Client, dbName, collectionName string) ([]CreativeTool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -77,3 +99,35 @@ func GetAllCreativeTools(client *mongo.Client, dbName, collectionName string) ([
 	}
 	return results, nil
 }
+
+// CurrentCreativeTools keeps the tools still in force.
+func CurrentCreativeTools(tools []CreativeTool) []CreativeTool {
+	var current []CreativeTool
+	for _, t := range tools {
+		if t.Current() {
+			current = append(current, t)
+		}
+	}
+	return current
+}
+
+// WithCreativeToolVersion returns tools as they would be after a new version of
+// tool took effect at from, without writing anything. A tool that does not exist
+// yet is added with tool.Index.
+func WithCreativeToolVersion(tools []CreativeTool, tool CreativeTool, from time.Time) ([]CreativeTool, error) {
+	out := make([]CreativeTool, 0, len(tools)+1)
+	for _, t := range tools {
+		if t.Team == tool.Team && t.ToolName == tool.ToolName && t.Current() {
+			if err := t.canFollow(from); err != nil {
+				return nil, err
+			}
+			to := from
+			t.EffectiveTo = &to
+			tool.Index = t.Index
+		}
+		out = append(out, t)
+	}
+	tool.ID = primitive.NilObjectID
+	tool.Effective = Effective{EffectiveFrom: &from}
+	return append(out, tool), nil
+}
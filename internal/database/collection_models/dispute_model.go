@@ -0,0 +1,212 @@
+package collectionmodels
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DISPUTE_OPEN     = "open"
+	DISPUTE_ACCEPTED = "accepted"
+	DISPUTE_REJECTED = "rejected"
+)
+
+// Dispute is a member's appeal against the level or tools of one of their
+// completed task records. Managers of the team discuss and resolve it; an
+// accepted dispute can patch the record with the proposed values.
+type Dispute struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	RecordID   primitive.ObjectID `bson:"record_id" json:"record_id"`
+	TaskID     string             `bson:"task_id" json:"task_id"`
+	TaskName   string             `bson:"task_name" json:"task_name"`
+	AssigneeID string             `bson:"assignee_id" json:"assignee_id"`
+	Team       string             `bson:"team" json:"team"`
+	// Level and Tool are the record's values when the dispute was opened;
+	// ProposedLevel and ProposedTool what the member asks for, nil / empty
+	// when that part is not disputed.
+	Level         int    `bson:"level" json:"level"`
+	Tool          []int  `bson:"tool" json:"tool"`
+	ProposedLevel *int   `bson:"proposed_level,omitempty" json:"proposed_level,omitempty"`
+	ProposedTool  []int  `bson:"proposed_tool,omitempty" json:"proposed_tool,omitempty"`
+	Opener        string `bson:"opener" json:"opener"`
+	Status        string `bson:"status" json:"status"`
+
+	CreatedAt  time.Time        `bson:"created_at" json:"created_at"`
+	ResolvedAt *time.Time       `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+	ResolvedBy string           `bson:"resolved_by,omitempty" json:"resolved_by,omitempty"`
+	Resolution string           `bson:"resolution,omitempty" json:"resolution,omitempty"`
+	Patched    bool             `bson:"patched,omitempty" json:"patched,omitempty"`
+	Comments   []DisputeComment `bson:"comments" json:"comments"`
+}
+
+type DisputeComment struct {
+	Author string    `bson:"author" json:"author"`
+	Text   string    `bson:"text" json:"text"`
+	At     time.Time `bson:"at" json:"at"`
+}
+
+// Age is how long the dispute has been open at now, or was open until resolved.
+func (d *Dispute) Age(now time.Time) time.Duration {
+	if d.ResolvedAt != nil {
+		return d.ResolvedAt.Sub(d.CreatedAt)
+	}
+	return now.Sub(d.CreatedAt)
+}
+
+func InsertDispute(client *mongo.Client, dbName, collectionName string, dispute *Dispute) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	res, err := collection.InsertOne(ctx, dispute)
+	if err != nil {
+		return err
+	}
+	dispute.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func GetDisputeByID(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) (*Dispute, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	var dispute Dispute
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&dispute); err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+// GetOpenDisputeByRecord returns the open dispute of a completed task record, or nil.
+func GetOpenDisputeByRecord(client *mongo.Client, dbName, collectionName string, recordID primitive.ObjectID) (*Dispute, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	var dispute Dispute
+	err := collection.FindOne(ctx, bson.M{"record_id": recordID, "status": DISPUTE_OPEN}).Decode(&dispute)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+// GetOpenDisputesByRecords maps the given completed task records to their open dispute.
+func GetOpenDisputesByRecords(client *mongo.Client, dbName, collectionName string, recordIDs []primitive.ObjectID) (map[primitive.ObjectID]Dispute, error) {
+	disputes := map[primitive.ObjectID]Dispute{}
+	if len(recordIDs) == 0 {
+		return disputes, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	cursor, err := collection.Find(ctx, bson.M{"record_id": bson.M{"$in": recordIDs}, "status": DISPUTE_OPEN})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var open []Dispute
+	if err := cursor.All(ctx, &open); err != nil {
+		return nil, err
+	}
+	for _, d := range open {
+		disputes[d.RecordID] = d
+	}
+	return disputes, nil
+}
+
+// GetDisputes lists disputes, oldest first, filtered by teams (all when empty),
+// status and opener when set.
+func GetDisputes(client *mongo.Client, dbName, collectionName string, teams []string, status, opener string, limit int64) ([]Dispute, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+
+	filter := bson.M{}
+	if len(teams) > 0 {
+		filter["team"] = bson.M{"$in": teams}
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+	if opener != "" {
+		filter["opener"] = opener
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	disputes := []Dispute{}
+	if err := cursor.All(ctx, &disputes); err != nil {
+		return nil, err
+	}
+	return disputes, nil
+}
+
+// AddDisputeComment appends a comment to an open dispute. It reports false when
+// the dispute is no longer open.
+func AddDisputeComment(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, comment DisputeComment) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	res, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": DISPUTE_OPEN},
+		bson.M{"$push": bson.M{"comments": comment}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// ResolveDispute closes an open dispute with status and appends the resolution
+// comment. It reports false when the dispute was no longer open.
+func ResolveDispute(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, status string, comment DisputeComment) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	res, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": DISPUTE_OPEN},
+		bson.M{
+			"$set":  bson.M{"status": status, "resolved_at": comment.At, "resolved_by": comment.Author, "resolution": comment.Text},
+			"$push": bson.M{"comments": comment},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// MarkDisputePatched records that an accepted dispute was written to its task record.
+func MarkDisputePatched(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"patched": true}})
+	return err
+}
+
+// ReopenDispute puts a dispute back to open after its patch could not be
+// applied, keeping the failure as a comment.
+func ReopenDispute(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, comment DisputeComment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.UpdateByID(ctx, id, bson.M{
+		"$set":   bson.M{"status": DISPUTE_OPEN},
+		"$unset": bson.M{"resolved_at": "", "resolved_by": "", "resolution": ""},
+		"$push":  bson.M{"comments": comment},
+	})
+	return err
+}
@@ -0,0 +1,117 @@
+package collectionmodels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrVersionConflict is returned when a new rule would be in force at the same
+// time as an existing version of it.
+var ErrVersionConflict = errors.New("rule version conflict")
+
+// Effective is the period a version of a scoring rule is in force:
+// [EffectiveFrom, EffectiveTo). An unset EffectiveFrom means since always, an
+// unset EffectiveTo means the version is still current.
+type Effective struct {
+	EffectiveFrom *time.Time `bson:"effective_from,omitempty"`
+	EffectiveTo   *time.Time `bson:"effective_to,omitempty"`
+}
+
+func (e *Effective) InForce(at time.Time) bool {
+	return (e.EffectiveFrom == nil || !at.Before(*e.EffectiveFrom)) && (e.EffectiveTo == nil || at.Before(*e.EffectiveTo))
+}
+
+func (e *Effective) Current() bool {
+	return e.EffectiveTo == nil
+}
+
+// canFollow checks that a new version starting at from can replace the current one.
+func (e *Effective) canFollow(from time.Time) error {
+	if e.EffectiveFrom != nil && !from.After(*e.EffectiveFrom) {
+		return fmt.Errorf("effective_from %s must be after the start of the current version (%s)", from.Format(time.RFC3339), e.EffectiveFrom.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// currentVersion matches the versions of a rule that are still in force.
+func currentVersion(filter bson.M) bson.M {
+	current := bson.M{"effective_to": nil}
+	for k, v := range filter {
+		current[k] = v
+	}
+	return current
+}
+
+// startVersion ends the current version of the rule selected by filter at from
+// and inserts next, which must already carry EffectiveFrom = from.
+func startVersion(ctx context.Context, collection *mongo.Collection, filter bson.M, from time.Time, next interface{}) error {
+	var current struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		Effective `bson:",inline"`
+	}
+	err := collection.FindOne(ctx, currentVersion(filter)).Decode(&current)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	if err == nil {
+		if err := current.canFollow(from); err != nil {
+			return err
+		}
+		if _, err := collection.UpdateByID(ctx, current.ID, bson.M{"$set": bson.M{"effective_to": from}}); err != nil {
+			return err
+		}
+	}
+	_, err = collection.InsertOne(ctx, next)
+	return err
+}
+
+// addVersion inserts next as the first version of the rule selected by filter,
+// or the one after it was retired. It fails with ErrVersionConflict while a
+// version is current, which must be replaced through startVersion instead, or
+// when next would start before the last version ended. An unset EffectiveFrom
+// (since always) is only kept for a rule that never existed; otherwise next
+// starts now.
+func addVersion(ctx context.Context, collection *mongo.Collection, filter bson.M, next *Effective, doc interface{}) error {
+	var versions []Effective
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return err
+	}
+	if err := canAddVersion(versions, next, time.Now().UTC()); err != nil {
+		return err
+	}
+	_, err = collection.InsertOne(ctx, doc)
+	return err
+}
+
+// canAddVersion is addVersion's check of next against the existing versions;
+// it sets next.EffectiveFrom to now when needed.
+func canAddVersion(versions []Effective, next *Effective, now time.Time) error {
+	if len(versions) > 0 && next.EffectiveFrom == nil {
+		next.EffectiveFrom = &now
+	}
+	for _, v := range versions {
+		if v.Current() {
+			return fmt.Errorf("%w: a version is already in force, update it instead", ErrVersionConflict)
+		}
+		if next.EffectiveFrom.Before(*v.EffectiveTo) {
+			return fmt.Errorf("%w: effective_from %s is before the previous version ended (%s)", ErrVersionConflict, next.EffectiveFrom.Format(time.RFC3339), v.EffectiveTo.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// endVersion retires the rule selected by filter at at; past scores keep using it.
+func endVersion(ctx context.Context, collection *mongo.Collection, filter bson.M, at time.Time) error {
+	_, err := collection.UpdateMany(ctx, currentVersion(filter), bson.M{"$set": bson.M{"effective_to": at}})
+	return err
+}
@@ -0,0 +1,48 @@
+package collectionmodels
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCanAddVersion(t *testing.T) {
+	day := func(d int) *time.Time {
+		at := time.Date(2025, time.January, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+	now := *day(20)
+
+	tests := []struct {
+		name     string
+		versions []Effective
+		next     Effective
+		wantErr  bool
+		wantFrom *time.Time
+	}{
+		{name: "first version since always", next: Effective{}, wantFrom: nil},
+		{name: "first version from a date", next: Effective{EffectiveFrom: day(6)}, wantFrom: day(6)},
+		{name: "a version is current", versions: []Effective{{EffectiveFrom: day(1)}}, next: Effective{EffectiveFrom: day(13)}, wantErr: true},
+		{name: "a version since always is current", versions: []Effective{{}}, next: Effective{}, wantErr: true},
+		{name: "after a retired version", versions: []Effective{{EffectiveFrom: day(1), EffectiveTo: day(6)}}, next: Effective{EffectiveFrom: day(6)}, wantFrom: day(6)},
+		{name: "overlapping a retired version", versions: []Effective{{EffectiveFrom: day(1), EffectiveTo: day(13)}}, next: Effective{EffectiveFrom: day(6)}, wantErr: true},
+		{name: "no date after a retired version starts now", versions: []Effective{{EffectiveTo: day(13)}}, next: Effective{}, wantFrom: day(20)},
+	}
+	for _, tt := range tests {
+		next := tt.next
+		err := canAddVersion(tt.versions, &next, now)
+		if tt.wantErr {
+			if !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("%s: error %v, want ErrVersionConflict", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if (next.EffectiveFrom == nil) != (tt.wantFrom == nil) || (next.EffectiveFrom != nil && !next.EffectiveFrom.Equal(*tt.wantFrom)) {
+			t.Errorf("%s: effective_from %v, want %v", tt.name, next.EffectiveFrom, tt.wantFrom)
+		}
+	}
+}
@@ -13,50 +13,60 @@ type Level struct {
 	ID         primitive.ObjectID `bson:"_id,omitempty"`
 	Team       string             `bson:"team"`
 	LevelPoint []int              `bson:"levelPoint"`
+	// A team has one level table per period; a task is scored with the one in force at its DoneDate.
+	Effective `bson:",inline"`
 }
 
-// Add to the databse a new level for a team
+// Add to the databse a new level table for a team that has none in force (see
+// addVersion); a current table is changed with UpdateLevelPointsForTeam.
 func AddNewLevelForTeam(client *mongo.Client, dbName, collectionName string, level *Level) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.InsertOne(ctx, level)
-	return err
+	level.EffectiveTo = nil
+	return addVersion(ctx, collection, bson.M{"team": level.Team}, &level.Effective, level)
 }
 
-// Update the level points for a team
+// Update the level points for a team. The current table is kept for the weeks
+// before level.EffectiveFrom (now when unset) and the new points apply from then on.
 func UpdateLevelPointsForTeam(client *mongo.Client, dbName, collectionName string, level *Level) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.UpdateOne(ctx,
-		bson.M{"team": level.Team},
-		bson.M{"$set": bson.M{"levelPoint": level.LevelPoint}},
-	)
-	return err
+	from := time.Now().UTC()
+	if level.EffectiveFrom != nil {
+		from = *level.EffectiveFrom
+	}
+	next := &Level{
+		Team:       level.Team,
+		LevelPoint: level.LevelPoint,
+		Effective:  Effective{EffectiveFrom: &from},
+	}
+	return startVersion(ctx, collection, bson.M{"team": level.Team}, from, next)
 }
 
-// Get the level points for a team
+// Get the level points currently in force for a team
 func GetLevelPointsForTeam(client *mongo.Client, dbName, collectionName, team string) (*Level, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
 	var level Level
-	err := collection.FindOne(ctx, bson.M{"team": team}).Decode(&level)
+	err := collection.FindOne(ctx, currentVersion(bson.M{"team": team})).Decode(&level)
 	if err != nil {
 		return nil, err
 	}
 	return &level, nil
 }
 
+// DeleteLevelForTeam retires the team's level table; weeks already scored with it keep it.
 func DeleteLevelForTeam(client *mongo.Client, dbName, collectionName, team string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collectionName)
-	_, err := collection.DeleteOne(ctx, bson.M{"team": team})
-	return err
+	return endVersion(ctx, collection, bson.M{"team": team}, time.Now().UTC())
 }
 
+// GetAllLevels returns every version of every team's level table.
 func GetAllLevels(client *mongo.Client, dbName, collectionName string) ([]Level, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -86,3 +96,31 @@ func GetAllLevelByTeam(client *mongo.Client, dbName, collectionName, team string
 	}
 	return levels, nil
 }
+
+// CurrentLevels keeps the level tables still in force.
+func CurrentLevels(levels []Level) []Level {
+	var current []Level
+	for _, l := range levels {
+		if l.Current() {
+			current = append(current, l)
+		}
+	}
+	return current
+}
+
+// WithLevelVersion returns levels as they would be after a new table for team
+// took effect at from, without writing anything.
+func WithLevelVersion(levels []Level, team string, points []int, from time.Time) ([]Level, error) {
+	out := make([]Level, 0, len(levels)+1)
+	for _, l := range levels {
+		if l.Team == team && l.Current() {
+			if err := l.canFollow(from); err != nil {
+				return nil, err
+			}
+			to := from
+			l.EffectiveTo = &to
+		}
+		out = append(out, l)
+	}
+	return append(out, Level{Team: team, LevelPoint: points, Effective: Effective{EffectiveFrom: &from}}), nil
+}
@@ -2,6 +2,9 @@ package collectionmodels
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -17,44 +20,251 @@ type Member struct {
 	Email    string             `bson:"email"`
 	Role     string             `bson:"role"`
 	Team     string             `bson:"team"`
+
+	// JoinedAt and LeftAt bound the latest employment period; a member with
+	// LeftAt set has left and only appears in historic data. Team and Role are
+	// the latest assignment, Assignments the dated history, closed when the
+	// member moves or leaves. Records from before the history was kept have
+	// none and count as on Team for their whole employment.
+	JoinedAt    *time.Time   `bson:"joined_at,omitempty"`
+	LeftAt      *time.Time   `bson:"left_at,omitempty"`
+	Assignments []Assignment `bson:"assignments,omitempty"`
+
+	// Identities are the other accounts trackers may report the member as, such
+	// as a personal email or a ClickUp user id. Tasks are stored under Email.
+	Identities []Identity `bson:"identities,omitempty"`
+}
+
+const (
+	IDENTITY_EMAIL   = "email"
+	IDENTITY_CLICKUP = "clickup"
+	IDENTITY_ASANA   = "asana"
+)
+
+// Identity is one account of a member: an email alias or a tracker user id.
+type Identity struct {
+	Kind  string `bson:"kind"`
+	Value string `bson:"value"`
+}
+
+// IdentityKey is how an account is looked up: emails are matched case-insensitively.
+func IdentityKey(kind, value string) string {
+	value = strings.TrimSpace(value)
+	if kind == IDENTITY_EMAIL {
+		value = strings.ToLower(value)
+	}
+	return kind + ":" + value
+}
+
+// IdentityKeys are the keys of every account of the member, Email included.
+func (m *Member) IdentityKeys() []string {
+	keys := []string{IdentityKey(IDENTITY_EMAIL, m.Email)}
+	for _, id := range m.Identities {
+		keys = append(keys, IdentityKey(id.Kind, id.Value))
+	}
+	return keys
+}
+
+// ValidateIdentities checks the kinds and values of the member's identities.
+func (m *Member) ValidateIdentities() error {
+	for _, id := range m.Identities {
+		switch id.Kind {
+		case IDENTITY_EMAIL, IDENTITY_CLICKUP, IDENTITY_ASANA:
+		default:
+			return fmt.Errorf("unknown identity kind %q", id.Kind)
+		}
+		if strings.TrimSpace(id.Value) == "" {
+			return fmt.Errorf("empty %s identity", id.Kind)
+		}
+	}
+	return nil
+}
+
+// Assignment is a member's team and role from From until To (open when nil).
+type Assignment struct {
+	Team string     `bson:"team"`
+	Role string     `bson:"role"`
+	From time.Time  `bson:"from"`
+	To   *time.Time `bson:"to,omitempty"`
+}
+
+// Employed reports whether the member was employed at at.
+func (m *Member) Employed(at time.Time) bool {
+	return m.AssignmentAt(at) != nil
+}
+
+// AssignmentAt is the member's team and role at at, or nil when they were not
+// employed then.
+func (m *Member) AssignmentAt(at time.Time) *Assignment {
+	if len(m.Assignments) == 0 {
+		if (m.JoinedAt != nil && at.Before(*m.JoinedAt)) || (m.LeftAt != nil && !at.Before(*m.LeftAt)) {
+			return nil
+		}
+		return &Assignment{Team: m.Team, Role: m.Role}
+	}
+	for i := range m.Assignments {
+		a := &m.Assignments[i]
+		if !at.Before(a.From) && (a.To == nil || at.Before(*a.To)) {
+			return a
+		}
+	}
+	return nil
+}
+
+// history is the member's assignments, seeding one from Team and Role for a
+// record from before the history was kept.
+func (m *Member) history() []Assignment {
+	if len(m.Assignments) > 0 || m.Team == "" {
+		return m.Assignments
+	}
+	from := time.Time{}
+	if m.JoinedAt != nil {
+		from = *m.JoinedAt
+	}
+	return []Assignment{{Team: m.Team, Role: m.Role, From: from}}
+}
+
+// closeAssignments ends the open assignment at at.
+func closeAssignments(assignments []Assignment, at time.Time) ([]Assignment, error) {
+	for i := range assignments {
+		if assignments[i].To != nil {
+			continue
+		}
+		if at.Before(assignments[i].From) {
+			return nil, fmt.Errorf("%s is before the current assignment started (%s)", at.Format(time.RFC3339), assignments[i].From.Format(time.RFC3339))
+		}
+		end := at
+		assignments[i].To = &end
+	}
+	return assignments, nil
+}
+
+// CurrentMemberFilter matches members who have not left.
+func CurrentMemberFilter() bson.M {
+	return bson.M{"left_at": bson.M{"$exists": false}}
 }
 
-func UpdateMemberToDataBase(client *mongo.Client, url, dbName, collName string, member *Member) error {
+// UpdateMemberToDataBase updates a member's details; identities are replaced when
+// member.Identities is not nil. A change of team or role is recorded as a new
+// assignment starting at at, closing the previous one.
+func UpdateMemberToDataBase(client *mongo.Client, url, dbName, collName string, member *Member, at time.Time) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
 
-	_, err := collection.UpdateOne(
-		ctx,
-		bson.M{"id": member.MemberID},
-		bson.M{"$set": bson.M{
-			"name":  member.Name,
-			"yob":   member.YOB,
-			"email": member.Email,
-			"role":  member.Role,
-			"team":  member.Team,
-		}},
-	)
+	var current Member
+	if err := collection.FindOne(ctx, bson.M{"id": member.MemberID}).Decode(&current); err != nil {
+		return err
+	}
+	set := bson.M{
+		"name":  member.Name,
+		"yob":   member.YOB,
+		"email": member.Email,
+	}
+	if member.Identities != nil {
+		set["identities"] = member.Identities
+	}
+	if member.Team != current.Team || member.Role != current.Role {
+		if current.LeftAt != nil {
+			return errors.New("member has left; rehire them before changing team or role")
+		}
+		assignments, err := closeAssignments(current.history(), at)
+		if err != nil {
+			return err
+		}
+		set["team"] = member.Team
+		set["role"] = member.Role
+		set["assignments"] = append(assignments, Assignment{Team: member.Team, Role: member.Role, From: at})
+	}
+
+	_, err := collection.UpdateOne(ctx, bson.M{"id": member.MemberID}, bson.M{"$set": set})
 	return err
 }
 
+// InsertMemberToDataBase adds a member joining at member.JoinedAt (now when unset)
+// with their first assignment.
 func InsertMemberToDataBase(client *mongo.Client, url, dbName, collName string, member *Member) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
 
+	if member.JoinedAt == nil {
+		now := time.Now().UTC()
+		member.JoinedAt = &now
+	}
+	if len(member.Assignments) == 0 {
+		member.Assignments = []Assignment{{Team: member.Team, Role: member.Role, From: *member.JoinedAt}}
+	}
 	_, err := collection.InsertOne(ctx, member)
 	return err
 }
 
-func DeleteMemberInDataBase(client *mongo.Client, url, dbName, collName, memberID string) error {
+// DeleteMemberInDataBase records that a member left at at. The record is kept so
+// their tasks stay attributed in historic reports; it only leaves current rosters.
+func DeleteMemberInDataBase(client *mongo.Client, url, dbName, collName, memberID string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+
+	var current Member
+	if err := collection.FindOne(ctx, bson.M{"id": memberID}).Decode(&current); err != nil {
+		return err
+	}
+	if current.LeftAt != nil {
+		return errors.New("member has already left")
+	}
+	assignments, err := closeAssignments(current.history(), at)
+	if err != nil {
+		return err
+	}
+	set := bson.M{"left_at": at}
+	if len(assignments) > 0 {
+		set["assignments"] = assignments
+	}
+	_, err = collection.UpdateOne(ctx, bson.M{"id": memberID}, bson.M{"$set": set})
+	return err
+}
+
+// RehireMember starts a new employment period at at, on the member's team and
+// role, for a member who left.
+func RehireMember(client *mongo.Client, url, dbName, collName, memberID string, team, role string, at time.Time) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	_, err := collection.DeleteOne(ctx, bson.M{"id": memberID})
+
+	var current Member
+	if err := collection.FindOne(ctx, bson.M{"id": memberID}).Decode(&current); err != nil {
+		return err
+	}
+	if current.LeftAt == nil {
+		return errors.New("member has not left")
+	}
+	if at.Before(*current.LeftAt) {
+		return fmt.Errorf("%s is before the member left (%s)", at.Format(time.RFC3339), current.LeftAt.Format(time.RFC3339))
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"id": memberID}, bson.M{
+		"$set": bson.M{
+			"team":        team,
+			"role":        role,
+			"joined_at":   at,
+			"assignments": append(current.history(), Assignment{Team: team, Role: role, From: at}),
+		},
+		"$unset": bson.M{"left_at": ""},
+	})
 	return err
 }
 
+func GetMemberByID(client *mongo.Client, url, dbName, collName, memberID string) (*Member, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	var member Member
+	if err := collection.FindOne(ctx, bson.M{"id": memberID}).Decode(&member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
 func GetMemberByEmail(client *mongo.Client, url, dbName, collName, email string) (*Member, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
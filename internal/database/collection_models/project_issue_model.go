@@ -106,6 +106,7 @@ func GetProjectIssue(client *mongo.Client, dbName, collectionName string, projec
 							bson.D{{Key: "$eq", Value: bson.A{"$task_type", "$$taskType"}}},
 							bson.D{{Key: "$gte", Value: bson.A{"$done_date", startTime}}},
 							bson.D{{Key: "$lte", Value: bson.A{"$done_date", endTime}}},
+							bson.D{{Key: "$ne", Value: bson.A{"$void", true}}},
 						}},
 					}},
 				}}},
@@ -214,6 +215,22 @@ func DeleteProjectIssue(client *mongo.Client, dbName, collectionName string, id
 	return err
 }
 
+// GetProjectIssueByID returns a stored project issue, or nil.
+func GetProjectIssueByID(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) (*ProjectIssue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	var issue ProjectIssue
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&issue)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
 func UpdateProjectIssue(client *mongo.Client, dbName, collectionName string, issue ProjectIssue) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
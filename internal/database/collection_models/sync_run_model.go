@@ -0,0 +1,130 @@
+package collectionmodels
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	SYNC_KIND_TEAM           = "team_sync"
+	SYNC_KIND_BACKFILL       = "backfill"
+	SYNC_KIND_PROJECT_REPORT = "project_report"
+
+	SYNC_TRIGGER_SCHEDULE = "schedule"
+	SYNC_TRIGGER_MANUAL   = "manual"
+	SYNC_TRIGGER_CLI      = "cli"
+
+	SYNC_STATUS_RUNNING = "running"
+	SYNC_STATUS_SUCCESS = "success"
+	SYNC_STATUS_PARTIAL = "partial"
+	SYNC_STATUS_FAILED  = "failed"
+)
+
+// SyncRun is the record of one sync job: what ran, over which window, and what
+// was accepted, skipped or rejected.
+type SyncRun struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Kind       string             `bson:"kind" json:"kind"`
+	Trigger    string             `bson:"trigger" json:"trigger"`
+	Team       string             `bson:"team,omitempty" json:"team,omitempty"`
+	WindowFrom time.Time          `bson:"window_from" json:"window_from"`
+	WindowTo   time.Time          `bson:"window_to" json:"window_to"`
+	StartedAt  time.Time          `bson:"started_at" json:"started_at"`
+	FinishedAt *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+	Status     string             `bson:"status" json:"status"`
+
+	Fetched  int   `bson:"fetched" json:"fetched"`
+	Accepted int   `bson:"accepted" json:"accepted"`
+	Skipped  int   `bson:"skipped" json:"skipped"`
+	Rejected int   `bson:"rejected" json:"rejected"`
+	Inserted int64 `bson:"inserted" json:"inserted"`
+
+	Rejections []TaskRejection `bson:"rejections,omitempty" json:"rejections,omitempty"`
+	Errors     []string        `bson:"errors,omitempty" json:"errors,omitempty"`
+}
+
+// SyncRunSummary is a SyncRun without the per-task details, for listings.
+type SyncRunSummary struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Kind       string             `bson:"kind" json:"kind"`
+	Trigger    string             `bson:"trigger" json:"trigger"`
+	Team       string             `bson:"team,omitempty" json:"team,omitempty"`
+	WindowFrom time.Time          `bson:"window_from" json:"window_from"`
+	WindowTo   time.Time          `bson:"window_to" json:"window_to"`
+	StartedAt  time.Time          `bson:"started_at" json:"started_at"`
+	FinishedAt *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+	Status     string             `bson:"status" json:"status"`
+	Fetched    int                `bson:"fetched" json:"fetched"`
+	Accepted   int                `bson:"accepted" json:"accepted"`
+	Skipped    int                `bson:"skipped" json:"skipped"`
+	Rejected   int                `bson:"rejected" json:"rejected"`
+	Inserted   int64              `bson:"inserted" json:"inserted"`
+}
+
+func InsertSyncRun(client *mongo.Client, dbName, collectionName string, run *SyncRun) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	res, err := collection.InsertOne(ctx, run)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(primitive.ObjectID); ok {
+		run.ID = id
+	}
+	return nil
+}
+
+func UpdateSyncRun(client *mongo.Client, dbName, collectionName string, run *SyncRun) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": run.ID}, run)
+	return err
+}
+
+// GetSyncRuns lists the most recent runs, optionally filtered by kind and team.
+func GetSyncRuns(client *mongo.Client, dbName, collectionName, kind, team string, limit int64) ([]SyncRunSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+
+	filter := bson.M{}
+	if kind != "" {
+		filter["kind"] = kind
+	}
+	if team != "" {
+		filter["team"] = team
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "started_at", Value: -1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"rejections": 0, "errors": 0})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var runs []SyncRunSummary
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func GetSyncRunByID(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID) (*SyncRun, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	var run SyncRun
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
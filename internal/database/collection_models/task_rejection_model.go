@@ -0,0 +1,124 @@
+package collectionmodels
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	REJECT_MISSING_DIFFICULTY = "missing_difficulty"
+	REJECT_INVALID_DIFFICULTY = "invalid_difficulty"
+	REJECT_MISSING_PROJECT    = "missing_project"
+	REJECT_INVALID_PROJECT    = "invalid_project"
+	REJECT_NO_ASSIGNEE        = "no_assignee"
+	REJECT_INVALID_CREDIT     = "invalid_credit"
+
+	REJECTION_ORIGIN_SYNC    = "sync"
+	REJECTION_ORIGIN_WEBHOOK = "webhook"
+)
+
+// TaskRejection is a ClickUp task that could not be turned into a completed task
+// because of its ClickUp fields.
+type TaskRejection struct {
+	TaskID     string    `bson:"task_id" json:"task_id"`
+	TaskName   string    `bson:"task_name" json:"task_name"`
+	URL        string    `bson:"url,omitempty" json:"url,omitempty"`
+	Team       string    `bson:"team" json:"team"`
+	AssigneeID string    `bson:"assignee_id,omitempty" json:"assignee_id,omitempty"`
+	Code       string    `bson:"code" json:"code"`
+	Reason     string    `bson:"reason" json:"reason"`
+	Week       time.Time `bson:"week" json:"week"`
+}
+
+// RejectedTask is the stored, deduplicated rejection of a task. It stays open
+// until the task is accepted (or deleted / reopened) on a later sync or webhook.
+type RejectedTask struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TaskRejection `bson:",inline"`
+	Origin        string     `bson:"origin" json:"origin"`
+	FirstSeen     time.Time  `bson:"first_seen" json:"first_seen"`
+	LastSeen      time.Time  `bson:"last_seen" json:"last_seen"`
+	ResolvedAt    *time.Time `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+}
+
+// UpsertRejectedTasks records rejections keyed on task and team. A rejection seen
+// again refreshes its reason and reopens it if it had been resolved.
+func UpsertRejectedTasks(client *mongo.Client, dbName, collectionName, origin string, rejections []TaskRejection, now time.Time) error {
+	if len(rejections) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+
+	models := make([]mongo.WriteModel, 0, len(rejections))
+	for _, r := range rejections {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"task_id": r.TaskID, "team": r.Team}).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"task_name":   r.TaskName,
+					"url":         r.URL,
+					"assignee_id": r.AssigneeID,
+					"code":        r.Code,
+					"reason":      r.Reason,
+					"week":        r.Week,
+					"origin":      origin,
+					"last_seen":   now,
+				},
+				"$setOnInsert": bson.M{"first_seen": now},
+				"$unset":       bson.M{"resolved_at": ""},
+			}).
+			SetUpsert(true))
+	}
+	_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// ResolveRejectedTasks closes the open rejections of the given tasks.
+func ResolveRejectedTasks(client *mongo.Client, dbName, collectionName string, taskIDs []string, now time.Time) (int64, error) {
+	if len(taskIDs) == 0 {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	res, err := collection.UpdateMany(ctx,
+		bson.M{"task_id": bson.M{"$in": taskIDs}, "resolved_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"resolved_at": now}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// GetOpenRejectedTasks lists the unresolved rejections of the given teams (all
+// teams when empty), newest week first.
+func GetOpenRejectedTasks(client *mongo.Client, dbName, collectionName string, teams []string) ([]RejectedTask, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+
+	filter := bson.M{"resolved_at": bson.M{"$exists": false}}
+	if len(teams) > 0 {
+		filter["team"] = bson.M{"$in": teams}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "week", Value: -1}, {Key: "assignee_id", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rejections []RejectedTask
+	if err := cursor.All(ctx, &rejections); err != nil {
+		return nil, err
+	}
+	return rejections, nil
+}
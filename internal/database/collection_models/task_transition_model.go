@@ -0,0 +1,66 @@
+package collectionmodels
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	TRANSITION_CREATED  = "created"
+	TRANSITION_UPDATED  = "updated"
+	TRANSITION_RESTORED = "restored"
+	TRANSITION_VOIDED   = "voided"
+)
+
+// TaskTransition is one change applied to a completed-task record, with the
+// record as it was before and after, so point changes can be traced back to
+// the ClickUp event that caused them.
+type TaskTransition struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TaskID   string             `bson:"task_id" json:"task_id"`
+	Action   string             `bson:"action" json:"action"`
+	Reason   string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	Event    string             `bson:"event" json:"event"`
+	EventID  string             `bson:"event_id" json:"event_id"`
+	Before   *CompletedTask     `bson:"before,omitempty" json:"before,omitempty"`
+	After    *CompletedTask     `bson:"after,omitempty" json:"after,omitempty"`
+	Occurred time.Time          `bson:"occurred_at" json:"occurred_at"`
+}
+
+func InsertTaskTransitions(client *mongo.Client, dbName, collectionName string, transitions []*TaskTransition) error {
+	if len(transitions) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	var docs []interface{}
+	for _, t := range transitions {
+		docs = append(docs, t)
+	}
+	_, err := collection.InsertMany(ctx, docs)
+	return err
+}
+
+func GetTaskTransitions(client *mongo.Client, dbName, collectionName, taskID string) ([]TaskTransition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"task_id": taskID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var transitions []TaskTransition
+	if err := cursor.All(ctx, &transitions); err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
@@ -0,0 +1,220 @@
+package collectionmodels
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	WEBHOOK_EVENT_PENDING    = "pending"
+	WEBHOOK_EVENT_PROCESSING = "processing"
+	WEBHOOK_EVENT_DONE       = "done"
+	WEBHOOK_EVENT_DEAD       = "dead"
+)
+
+// WebhookEvent is the inbox entry of an accepted webhook delivery. The unique
+// event id makes a replayed (or retried) delivery land on the same entry, and
+// the status fields drive the background workers that process it.
+type WebhookEvent struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	EventID    string             `bson:"event_id" json:"event_id"`
+	WebhookID  string             `bson:"webhook_id" json:"webhook_id"`
+	Event      string             `bson:"event" json:"event"`
+	TaskID     string             `bson:"task_id" json:"task_id"`
+	Kind       string             `bson:"kind" json:"kind"`
+	Payload    string             `bson:"payload" json:"payload"`
+	ReceivedAt time.Time          `bson:"received_at" json:"received_at"`
+
+	Status        string     `bson:"status" json:"status"`
+	Attempts      int        `bson:"attempts" json:"attempts"`
+	NextAttemptAt time.Time  `bson:"next_attempt_at" json:"next_attempt_at"`
+	LockedUntil   *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	LastError     string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	ProcessedAt   *time.Time `bson:"processed_at,omitempty" json:"processed_at,omitempty"`
+	// Only set once the event is done; pending and dead events never expire.
+	ExpiresAt *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+}
+
+func EnsureWebhookEventIndexes(client *mongo.Client, dbName, collectionName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "event_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+	})
+	return err
+}
+
+// InsertWebhookEvent queues the event and reports false when the event id was already seen.
+func InsertWebhookEvent(client *mongo.Client, dbName, collectionName string, event *WebhookEvent) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	if event.Status == "" {
+		event.Status = WEBHOOK_EVENT_PENDING
+	}
+	if event.NextAttemptAt.IsZero() {
+		event.NextAttemptAt = event.ReceivedAt
+	}
+	res, err := collection.InsertOne(ctx, event)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if id, ok := res.InsertedID.(primitive.ObjectID); ok {
+		event.ID = id
+	}
+	return true, nil
+}
+
+// ClaimWebhookEvent picks the oldest event that is due, or whose worker lease ran
+// out, marks it processing until now+lease and counts the attempt. It returns nil
+// when there is nothing to do.
+func ClaimWebhookEvent(client *mongo.Client, dbName, collectionName string, now time.Time, lease time.Duration) (*WebhookEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": WEBHOOK_EVENT_PENDING, "next_attempt_at": bson.M{"$lte": now}},
+		bson.M{"status": WEBHOOK_EVENT_PROCESSING, "locked_until": bson.M{"$lte": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{"status": WEBHOOK_EVENT_PROCESSING, "locked_until": now.Add(lease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var event WebhookEvent
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&event)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// DeferWebhookEvent puts a claimed event back in the queue until nextAttemptAt
+// without counting the attempt, e.g. while another event of its task is processed.
+func DeferWebhookEvent(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, nextAttemptAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.UpdateByID(ctx, id, bson.M{
+		"$set":   bson.M{"status": WEBHOOK_EVENT_PENDING, "next_attempt_at": nextAttemptAt},
+		"$inc":   bson.M{"attempts": -1},
+		"$unset": bson.M{"locked_until": ""},
+	})
+	return err
+}
+
+// AcquireWebhookTaskLease takes the lease on a task for event until now+lease, so
+// two events of the same task are never processed at once. It reports false when
+// another event holds an unexpired lease. The lease documents are keyed by task id.
+func AcquireWebhookTaskLease(client *mongo.Client, dbName, collectionName, taskID string, event primitive.ObjectID, now time.Time, lease time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	filter := bson.M{"_id": taskID, "$or": bson.A{
+		bson.M{"locked_until": bson.M{"$lte": now}},
+		bson.M{"event": event},
+	}}
+	update := bson.M{"$set": bson.M{"event": event, "locked_until": now.Add(lease)}}
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// The lease exists and is held by another event.
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReleaseWebhookTaskLease drops the lease on a task if event still holds it.
+func ReleaseWebhookTaskLease(client *mongo.Client, dbName, collectionName, taskID string, event primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": taskID, "event": event})
+	return err
+}
+
+func MarkWebhookEventDone(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, processedAt, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	_, err := collection.UpdateByID(ctx, id, bson.M{
+		"$set":   bson.M{"status": WEBHOOK_EVENT_DONE, "processed_at": processedAt, "expires_at": expiresAt},
+		"$unset": bson.M{"locked_until": "", "last_error": ""},
+	})
+	return err
+}
+
+// MarkWebhookEventFailed schedules another attempt at nextAttemptAt, or moves the
+// event to the dead-letter list when dead is true.
+func MarkWebhookEventFailed(client *mongo.Client, dbName, collectionName string, id primitive.ObjectID, lastError string, nextAttemptAt time.Time, dead bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	status := WEBHOOK_EVENT_PENDING
+	if dead {
+		status = WEBHOOK_EVENT_DEAD
+	}
+	_, err := collection.UpdateByID(ctx, id, bson.M{
+		"$set":   bson.M{"status": status, "last_error": lastError, "next_attempt_at": nextAttemptAt},
+		"$unset": bson.M{"locked_until": ""},
+	})
+	return err
+}
+
+func GetDeadWebhookEvents(client *mongo.Client, dbName, collectionName string, limit int64) ([]WebhookEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	opts := options.Find().SetSort(bson.D{{Key: "received_at", Value: -1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, bson.M{"status": WEBHOOK_EVENT_DEAD}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []WebhookEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ReplayDeadWebhookEvents puts dead events back in the queue with a fresh attempt
+// budget. With no ids every dead event is replayed.
+func ReplayDeadWebhookEvents(client *mongo.Client, dbName, collectionName string, ids []primitive.ObjectID, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collectionName)
+	filter := bson.M{"status": WEBHOOK_EVENT_DEAD}
+	if len(ids) > 0 {
+		filter["_id"] = bson.M{"$in": ids}
+	}
+	res, err := collection.UpdateMany(ctx, filter, bson.M{
+		"$set":   bson.M{"status": WEBHOOK_EVENT_PENDING, "attempts": 0, "next_attempt_at": now},
+		"$unset": bson.M{"last_error": ""},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
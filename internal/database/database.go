@@ -6,9 +6,11 @@ import (
 	"log"
 	"os"
 	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/scoring"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -41,271 +43,32 @@ type TeamWeeklyTarget struct {
 	WeeklyTarget int32  `bson:"point"`
 }
 
-type PerformancePoint struct {
-	StartWeek                 time.Time `bson:"_id"`
-	TotalPerformancePoint     float64   `bson:"total_performance_point"`
-	TotalCreativeProcessPoint float64   `bson:"total_creative_process_point"`
-	TotalCreativeTaskPoint    float64   `bson:"total_creative_task_point"`
-	TotalBasePoint            float64   `bson:"total_base_point"`
-	Identifier                string    `bson:"identifier"`
-}
-
 type TeamRole struct {
 	Team string `bson:"team"`
 	Role string `bson:"role"`
 }
 
-func GetPerformancePoint(uri, dbName, collName, identifier string, startDate, endDate time.Time, isTeam bool) ([]*PerformancePoint, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-	// defer client.Disconnect(ctx)
-	collection := client.Database(dbName).Collection(collName)
-
-	var identifierKey string
-	if isTeam {
-		identifierKey = "team"
-	} else {
-		identifierKey = "assignee_id"
-	}
-
-	pipeline := mongo.Pipeline{
-		// 1. $match
-		{{Key: "$match", Value: bson.D{
-			{Key: identifierKey, Value: identifier},
-			{Key: "done_date", Value: bson.D{
-				{Key: "$gte", Value: startDate},
-				{Key: "$lte", Value: endDate},
-			}},
-		}}},
-		// 2. $addFields: week_start via $dateTrunc
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "week_start", Value: bson.D{
-				{Key: "$dateTrunc", Value: bson.D{
-					{Key: "date", Value: "$done_date"},
-					{Key: "unit", Value: "week"},
-					{Key: "binSize", Value: 1},
-					{Key: "startOfWeek", Value: "monday"},
-				}},
-			}},
-		}}},
-		// 3. $lookup level theo team
-		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "level"},
-			{Key: "localField", Value: "team"},
-			{Key: "foreignField", Value: "team"},
-			{Key: "as", Value: "level_info"},
-		}}},
-		{{Key: "$unwind", Value: "$level_info"}},
-
-		// 4. Tính performance_point từ levelPoint
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "performance_point", Value: bson.D{
-				{Key: "$let", Value: bson.D{
-					{Key: "vars", Value: bson.D{
-						{Key: "idx", Value: bson.D{{Key: "$subtract", Value: bson.A{"$level", 1}}}},
-						{Key: "arr", Value: "$level_info.levelPoint"},
-					}},
-					{Key: "in", Value: bson.D{
-						{Key: "$cond", Value: bson.A{
-							bson.D{{Key: "$lt", Value: bson.A{"$$idx", bson.D{{Key: "$size", Value: "$$arr"}}}}},
-							bson.D{{Key: "$arrayElemAt", Value: bson.A{"$$arr", "$$idx"}}},
-							"$level",
-						}},
-					}},
-				}},
-			}},
-		}}},
-
-		// 5. $lookup creative-tool theo tool
-		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "creative-tool"},
-			{Key: "localField", Value: "tool"},
-			{Key: "foreignField", Value: "index"},
-			{Key: "as", Value: "tool_info"},
-		}}},
-
-		// 6. Tách tool loại t và q, map points
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "tool_points_t", Value: bson.D{
-				{Key: "$map", Value: bson.D{
-					{Key: "input", Value: bson.D{
-						{Key: "$filter", Value: bson.D{
-							{Key: "input", Value: "$tool_info"},
-							{Key: "as", Value: "t"},
-							{Key: "cond", Value: bson.D{{Key: "$eq", Value: bson.A{"$$t.type", "t"}}}},
-						}},
-					}},
-					{Key: "as", Value: "t"},
-					{Key: "in", Value: bson.D{
-						{Key: "index", Value: "$$t.index"},
-						{Key: "point", Value: bson.D{
-							{Key: "$let", Value: bson.D{
-								{Key: "vars", Value: bson.D{
-									{Key: "idx", Value: bson.D{{Key: "$subtract", Value: bson.A{"$level", 1}}}},
-									{Key: "arr", Value: "$$t.point"},
-								}},
-								{Key: "in", Value: bson.D{
-									{Key: "$cond", Value: bson.A{
-										bson.D{{Key: "$lt", Value: bson.A{"$$idx", bson.D{{Key: "$size", Value: "$$arr"}}}}},
-										bson.D{{Key: "$arrayElemAt", Value: bson.A{"$$arr", "$$idx"}}},
-										bson.D{{Key: "$arrayElemAt", Value: bson.A{"$$arr", 0}}},
-									}},
-								}},
-							}},
-						}},
-					}},
-				}},
-			}},
-			{Key: "tool_points_q", Value: bson.D{
-				{Key: "$map", Value: bson.D{
-					{Key: "input", Value: bson.D{
-						{Key: "$filter", Value: bson.D{
-							{Key: "input", Value: "$tool_info"},
-							{Key: "as", Value: "t"},
-							{Key: "cond", Value: bson.D{{Key: "$eq", Value: bson.A{"$$t.type", "q"}}}},
-						}},
-					}},
-					{Key: "as", Value: "t"},
-					{Key: "in", Value: bson.D{
-						{Key: "index", Value: "$$t.index"},
-						{Key: "point", Value: bson.D{{Key: "$arrayElemAt", Value: bson.A{"$$t.point", 0}}}},
-					}},
-				}},
-			}},
-		}}},
-
-		// 7. Tính tool_factor và creative_process_point
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "tool_factor", Value: bson.D{
-				{Key: "$cond", Value: bson.D{
-					{Key: "if", Value: bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$size", Value: "$tool_points_t"}}, 0}}}},
-					{Key: "then", Value: 0},
-					{Key: "else", Value: bson.D{
-						{Key: "$reduce", Value: bson.D{
-							{Key: "input", Value: bson.D{
-								{Key: "$map", Value: bson.D{
-									{Key: "input", Value: "$tool_points_t"},
-									{Key: "as", Value: "tp"},
-									{Key: "in", Value: bson.D{
-										{Key: "$cond", Value: bson.A{
-											bson.D{{Key: "$lt", Value: bson.A{"$$tp.point", 1}}},
-											"$$tp.point",
-											1,
-										}},
-									}},
-								}},
-							}},
-							{Key: "initialValue", Value: 1},
-							{Key: "in", Value: bson.D{{Key: "$multiply", Value: bson.A{"$$value", "$$this"}}}},
-						}},
-					}},
-				}},
-			}},
-			{Key: "creative_process_point", Value: bson.D{
-				{Key: "$sum", Value: bson.D{
-					{Key: "$map", Value: bson.D{
-						{Key: "input", Value: "$tool_points_q"},
-						{Key: "as", Value: "tp"},
-						{Key: "in", Value: "$$tp.point"},
-					}},
-				}},
-			}},
-		}}},
-
-		// 8. creative_task_point = performance_point * tool_factor
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "creative_task_point", Value: bson.D{
-				{Key: "$multiply", Value: bson.A{"$performance_point", "$tool_factor"}},
-			}},
-		}}},
-
-		// 9. base_point
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "base_point", Value: bson.D{
-				{Key: "$subtract", Value: bson.A{"$performance_point", "$creative_task_point"}},
-			}},
-		}}},
-
-		// 10. $project các field cần thiết
-		{{Key: "$project", Value: bson.D{
-			{Key: "task_name", Value: 1},
-			{Key: "assignee_id", Value: 1},
-			{Key: "team", Value: 1},
-			{Key: "level", Value: 1},
-			{Key: "performance_point", Value: 1},
-			{Key: "tool_points_t", Value: 1},
-			{Key: "tool_points_q", Value: 1},
-			{Key: "tool_factor", Value: 1},
-			{Key: "creative_process_point", Value: 1},
-			{Key: "creative_task_point", Value: 1},
-			{Key: "base_point", Value: 1},
-			{Key: "done_date", Value: 1},
-			{Key: "week_start", Value: 1},
-		}}},
-
-		// 11. Group theo tuần
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$week_start"},
-			{Key: "total_performance_point", Value: bson.D{{Key: "$sum", Value: "$performance_point"}}},
-			{Key: "total_creative_process_point", Value: bson.D{{Key: "$sum", Value: "$creative_process_point"}}},
-			{Key: "total_creative_task_point", Value: bson.D{{Key: "$sum", Value: "$creative_task_point"}}},
-			{Key: "total_base_point", Value: bson.D{{Key: "$sum", Value: "$base_point"}}},
-			{Key: "tasks", Value: bson.D{{Key: "$push", Value: "$$ROOT"}}},
-		}}},
-
-		// 12. Sort theo tuần
-		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
-	}
-
-	cursor, err := collection.Aggregate(ctx, pipeline)
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(ctx)
-
-	var results []*PerformancePoint
-	if err = cursor.All(ctx, &results); err != nil {
-		return nil, err
-	}
-
-	if len(results) == 0 {
-		return nil, nil
-	}
-
-	for _, r := range results {
-		r.Identifier = identifier
-	}
-	return results, nil
-}
-
+// GetMembersByTeam returns the current members of team, or every current member when team is "".
 func GetMembersByTeam(uri, dbName, collName string, team string) ([]*collectionmodels.Member, error) {
 	// Example body request
 	// 	{
 	//     "teams": ["Art Creative"]
 	// 	}
+	return GetMembersByTeamAt(uri, dbName, collName, team, time.Now().UTC())
+}
 
+// GetMembersByTeamAt returns who was on team at at (everyone employed then when
+// team is ""), with Team and Role as they were at that time. Members who have
+// since moved or left are included.
+func GetMembersByTeamAt(uri, dbName, collName string, team string, at time.Time) ([]*collectionmodels.Member, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 	// defer client.Disconnect(ctx)
 	collection := client.Database(dbName).Collection(collName)
 
-	// if team == "" or null return all collection
-	if team == "" {
-		cursor, err := collection.Find(ctx, bson.M{})
-		if err != nil {
-			return nil, err
-		}
-		defer cursor.Close(ctx)
-
-		var results []*collectionmodels.Member
-		if err = cursor.All(ctx, &results); err != nil {
-			return nil, err
-		}
-		return results, nil
-	}
-
-	filter := bson.M{
-		"team": team,
+	filter := bson.M{}
+	if team != "" {
+		filter = bson.M{"$or": bson.A{bson.M{"team": team}, bson.M{"assignments.team": team}}}
 	}
 	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
@@ -313,11 +76,21 @@ func GetMembersByTeam(uri, dbName, collName string, team string) ([]*collectionm
 	}
 	defer cursor.Close(ctx)
 
-	var results []*collectionmodels.Member
-	if err = cursor.All(ctx, &results); err != nil {
+	var members []*collectionmodels.Member
+	if err = cursor.All(ctx, &members); err != nil {
 		return nil, err
 	}
 
+	var results []*collectionmodels.Member
+	for _, member := range members {
+		assignment := member.AssignmentAt(at)
+		if assignment == nil || (team != "" && assignment.Team != team) {
+			continue
+		}
+		member.Team, member.Role = assignment.Team, assignment.Role
+		results = append(results, member)
+	}
+
 	if len(results) == 0 {
 		return nil, nil
 	}
@@ -341,7 +114,7 @@ func IsEmailInDatabase(uri, dbName, collName, email string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
-	filter := bson.M{"email": email}
+	filter := bson.M{"email": email, "left_at": bson.M{"$exists": false}}
 	count, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return false, err
@@ -356,7 +129,7 @@ func GetMemberRoles(uri, dbName, collName, email string) ([]*TeamRole, error) {
 
 	pipeline := mongo.Pipeline{
 		{{
-			Key: "$match", Value: bson.D{{Key: "email", Value: email}},
+			Key: "$match", Value: bson.D{{Key: "email", Value: email}, {Key: "left_at", Value: bson.M{"$exists": false}}},
 		}},
 		{{
 			Key: "$project", Value: bson.D{
@@ -385,6 +158,9 @@ func GetAllTeams(uri, dbName, collName string) ([]*Team, error) {
 	defer cancel()
 	collection := client.Database(dbName).Collection(collName)
 	pipeline := mongo.Pipeline{
+		{{
+			Key: "$match", Value: collectionmodels.CurrentMemberFilter(),
+		}},
 		{{
 			Key: "$group", Value: bson.D{
 				{Key: "_id", Value: "$team"},
@@ -443,13 +219,7 @@ func GetMongoClient() *mongo.Client {
 }
 
 // Lấy tổng điểm trong khoảng thời gian, không chia theo tuần
-type PerformancePointTotal struct {
-	TotalPerformancePoint     float64 `bson:"total_performance_point"`
-	TotalCreativeProcessPoint float64 `bson:"total_creative_process_point"`
-	TotalCreativeTaskPoint    float64 `bson:"total_creative_task_point"`
-	TotalBasePoint            float64 `bson:"total_base_point"`
-	Identifier                string  `bson:"identifier"`
-}
+type PerformancePointTotal = scoring.Totals
 
 type PerformancePointTotalWithTime struct {
 	StartDate             time.Time             `bson:"start_date"`
@@ -457,10 +227,7 @@ type PerformancePointTotalWithTime struct {
 	TotalPerformancePoint PerformancePointTotal `bson:"total_performance_point"`
 }
 
-type ToolPointEntry struct {
-	Index int     `bson:"index"`
-	Point float64 `bson:"point"`
-}
+type ToolPointEntry = scoring.ToolPoint
 
 type TaskEntry struct {
 	TaskName             string           `bson:"task_name"`
@@ -476,223 +243,22 @@ type TaskEntry struct {
 	CreativeTaskPoint    float64          `bson:"creative_task_point"`
 	BasePoint            float64          `bson:"base_point"`
 	DoneDate             time.Time        `bson:"done_date"`
-}
-
-func GetPerformancePointTotal(uri, dbName, collName, identifier string, startDate, endDate time.Time, isTeam bool) (*PerformancePointTotal, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-	collection := client.Database(dbName).Collection(collName)
-
-	var identifierKey string
-	if isTeam {
-		identifierKey = "team"
-	} else {
-		identifierKey = "assignee_id"
-	}
-
-	pipeline := mongo.Pipeline{
-		// 1. Match theo done_date
-		{{Key: "$match", Value: bson.D{
-			{Key: identifierKey, Value: identifier},
-			{Key: "done_date", Value: bson.D{
-				{Key: "$gte", Value: startDate},
-				{Key: "$lte", Value: endDate},
-			}},
-		}}},
-
-		// 2. Lookup level theo team
-		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "level"},
-			{Key: "localField", Value: "team"},
-			{Key: "foreignField", Value: "team"},
-			{Key: "as", Value: "level_info"},
-		}}},
-		{{Key: "$unwind", Value: "$level_info"}},
-
-		// 3. Tính performance_point
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "performance_point", Value: bson.D{
-				{Key: "$let", Value: bson.D{
-					{Key: "vars", Value: bson.D{
-						{Key: "idx", Value: bson.D{{Key: "$subtract", Value: bson.A{"$level", 1}}}},
-						{Key: "arr", Value: "$level_info.levelPoint"},
-					}},
-					{Key: "in", Value: bson.D{
-						{Key: "$cond", Value: bson.A{
-							bson.D{{Key: "$lt", Value: bson.A{"$$idx", bson.D{{Key: "$size", Value: "$$arr"}}}}},
-							bson.D{{Key: "$arrayElemAt", Value: bson.A{"$$arr", "$$idx"}}},
-							"$level",
-						}},
-					}},
-				}},
-			}},
-		}}},
-
-		// 4. Lookup creative-tool theo tool
-		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "creative-tool"},
-			{Key: "localField", Value: "tool"},
-			{Key: "foreignField", Value: "index"},
-			{Key: "as", Value: "tool_info"},
-		}}},
-
-		// 5. Tách tool loại t và q
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "tool_points_t", Value: bson.D{
-				{Key: "$map", Value: bson.D{
-					{Key: "input", Value: bson.D{
-						{Key: "$filter", Value: bson.D{
-							{Key: "input", Value: "$tool_info"},
-							{Key: "as", Value: "t"},
-							{Key: "cond", Value: bson.D{{Key: "$eq", Value: bson.A{"$$t.type", "t"}}}},
-						}},
-					}},
-					{Key: "as", Value: "t"},
-					{Key: "in", Value: bson.D{
-						{Key: "index", Value: "$$t.index"},
-						{Key: "point", Value: bson.D{
-							{Key: "$let", Value: bson.D{
-								{Key: "vars", Value: bson.D{
-									{Key: "idx", Value: bson.D{{Key: "$subtract", Value: bson.A{"$level", 1}}}},
-									{Key: "arr", Value: "$$t.point"},
-								}},
-								{Key: "in", Value: bson.D{
-									{Key: "$cond", Value: bson.A{
-										bson.D{{Key: "$lt", Value: bson.A{"$$idx", bson.D{{Key: "$size", Value: "$$arr"}}}}},
-										bson.D{{Key: "$arrayElemAt", Value: bson.A{"$$arr", "$$idx"}}},
-										bson.D{{Key: "$arrayElemAt", Value: bson.A{"$$arr", 0}}},
-									}},
-								}},
-							}},
-						}},
-					}},
-				}},
-			}},
-			{Key: "tool_points_q", Value: bson.D{
-				{Key: "$map", Value: bson.D{
-					{Key: "input", Value: bson.D{
-						{Key: "$filter", Value: bson.D{
-							{Key: "input", Value: "$tool_info"},
-							{Key: "as", Value: "t"},
-							{Key: "cond", Value: bson.D{{Key: "$eq", Value: bson.A{"$$t.type", "q"}}}},
-						}},
-					}},
-					{Key: "as", Value: "t"},
-					{Key: "in", Value: bson.D{
-						{Key: "index", Value: "$$t.index"},
-						{Key: "point", Value: bson.D{{Key: "$arrayElemAt", Value: bson.A{"$$t.point", 0}}}},
-					}},
-				}},
-			}},
-		}}},
-
-		// 6. Tính tool_factor và creative_process_point
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "tool_factor", Value: bson.D{
-				{Key: "$cond", Value: bson.D{
-					{Key: "if", Value: bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$size", Value: "$tool_points_t"}}, 0}}}},
-					{Key: "then", Value: 0},
-					{Key: "else", Value: bson.D{
-						{Key: "$reduce", Value: bson.D{
-							{Key: "input", Value: bson.D{
-								{Key: "$map", Value: bson.D{
-									{Key: "input", Value: "$tool_points_t"},
-									{Key: "as", Value: "tp"},
-									{Key: "in", Value: bson.D{
-										{Key: "$cond", Value: bson.A{
-											bson.D{{Key: "$lt", Value: bson.A{"$$tp.point", 1}}},
-											"$$tp.point",
-											1,
-										}},
-									}},
-								}},
-							}},
-							{Key: "initialValue", Value: 1},
-							{Key: "in", Value: bson.D{{Key: "$multiply", Value: bson.A{"$$value", "$$this"}}}},
-						}},
-					}},
-				}},
-			}},
-			{Key: "creative_process_point", Value: bson.D{
-				{Key: "$sum", Value: bson.D{
-					{Key: "$map", Value: bson.D{
-						{Key: "input", Value: "$tool_points_q"},
-						{Key: "as", Value: "tp"},
-						{Key: "in", Value: "$$tp.point"},
-					}},
-				}},
-			}},
-		}}},
+	Source               string           `bson:"source"`
 
-		// 7. Tính creative_task_point
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "creative_task_point", Value: bson.D{
-				{Key: "$multiply", Value: bson.A{"$performance_point", "$tool_factor"}},
-			}},
-		}}},
-
-		// 8. Tính base_point
-		{{Key: "$addFields", Value: bson.D{
-			{Key: "base_point", Value: bson.D{
-				{Key: "$subtract", Value: bson.A{"$performance_point", "$creative_task_point"}},
-			}},
-		}}},
-
-		// 9. Project các field cần thiết
-		{{Key: "$project", Value: bson.D{
-			{Key: "task_name", Value: 1},
-			{Key: "assignee_id", Value: 1},
-			{Key: "team", Value: 1},
-			{Key: "level", Value: 1},
-			{Key: "performance_point", Value: 1},
-			{Key: "tool_points_t", Value: 1},
-			{Key: "tool_points_q", Value: 1},
-			{Key: "tool_factor", Value: 1},
-			{Key: "creative_process_point", Value: 1},
-			{Key: "creative_task_point", Value: 1},
-			{Key: "base_point", Value: 1},
-			{Key: "done_date", Value: 1},
-		}}},
+	// Share is the part of the task credited to the identifier; the points above are already scaled by it.
+	Share        float64                        `bson:"share"`
+	Contributors []collectionmodels.Contributor `bson:"contributors,omitempty"`
 
-		// 10. Group để tính tổng toàn bộ
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: nil},
-			{Key: "total_performance_point", Value: bson.D{{Key: "$sum", Value: "$performance_point"}}},
-			{Key: "total_creative_process_point", Value: bson.D{{Key: "$sum", Value: "$creative_process_point"}}},
-			{Key: "total_creative_task_point", Value: bson.D{{Key: "$sum", Value: "$creative_task_point"}}},
-			{Key: "total_base_point", Value: bson.D{{Key: "$sum", Value: "$base_point"}}},
-			{Key: "tasks", Value: bson.D{{Key: "$push", Value: "$$ROOT"}}},
-		}}},
-	}
-
-	cursor, err := collection.Aggregate(ctx, pipeline)
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(ctx)
-
-	var results []PerformancePointTotal
-	if err = cursor.All(ctx, &results); err != nil {
-		return nil, err
-	}
-
-	if len(results) == 0 {
-		return nil, nil
-	}
-
-	results[0].Identifier = identifier
-	return &results[0], nil
+	// TaskID identifies the task; OpenDispute is the id of its open score dispute, if any.
+	TaskID      string `bson:"id"`
+	OpenDispute string `bson:"open_dispute,omitempty"`
 }
 
+
 func GetPerformancePoints (client *mongo.Client, dbName, collectionName string, identifier string, startDate, endDate time.Time, isTeam, isWeekly bool) ([]PerformancePointTotalWithTime, error) {
 
 	// Slide the startDate to to the EndDate using Monday
-	level, err := collectionmodels.GetAllLevels(client, dbName, os.Getenv("MONGODB_COLLECTION_LEVEL"))
-	if err != nil {
-		return nil, err
-	}
-
-	toolList, err := collectionmodels.GetAllCreativeTools(client, dbName, os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"))
+	rules, err := scoring.LoadRules(client, dbName)
 	if err != nil {
 		return nil, err
 	}
@@ -710,7 +276,7 @@ func GetPerformancePoints (client *mongo.Client, dbName, collectionName string,
 			if len(taskList) == 0 {
 				continue
 			}
-			per := GetPerformancePointTotals(identifier, taskList, level, toolList)
+			per := rules.Total(identifier, taskList)
 			res := PerformancePointTotalWithTime{
 				StartDate:             dateRange[0],
 				EndDate:               dateRange[1],
@@ -729,7 +295,7 @@ func GetPerformancePoints (client *mongo.Client, dbName, collectionName string,
 		return nil, nil
 	}
 
-	per := GetPerformancePointTotals(identifier, tasks, level, toolList)
+	per := rules.Total(identifier, tasks)
 	res := PerformancePointTotalWithTime{
 		StartDate:             startDate,
 		EndDate:               endDate,
@@ -739,61 +305,29 @@ func GetPerformancePoints (client *mongo.Client, dbName, collectionName string,
 	return results, nil
 }
 
-func buildToolPoints(toolList []collectionmodels.CreativeTool, inUsed []int, level int, team string) ([]ToolPointEntry, []ToolPointEntry) {
-	var toolForTeam []collectionmodels.CreativeTool
-	for _, t := range toolList {
-		if t.Team == team {
-			toolForTeam = append(toolForTeam, t)
-		}
-	}
-	var toolPointsT, toolPointsQ []ToolPointEntry
-	for _, idx := range inUsed {
-		for _, t := range toolForTeam {
-			if t.Index == idx {
-				if t.Type == "t" {
-					var point float64
-					if level > 0 && level <= len(t.Point) {
-						point = t.Point[level-1]
-					} else if len(t.Point) > 0 {
-						point = t.Point[0]
-					}
-					toolPointsT = append(toolPointsT, ToolPointEntry{Index: t.Index, Point: point})
-				} else {
-					var point float64
-					if len(t.Point) > 0 {
-						point = t.Point[0]
-					}
-					toolPointsQ = append(toolPointsQ, ToolPointEntry{Index: t.Index, Point: point})
-				}
-			}
-		}
-	}
-	return toolPointsT, toolPointsQ
-}
-
 func GetTaskEntries(client *mongo.Client, dbName, collectionName string, identifier string, startDate, endDate time.Time, isTeam, isWeekly bool) ([]TaskEntry, error) {
 	tasks, err := collectionmodels.GetCompletedTasksByDateRange(client, dbName, collectionName, isTeam, identifier, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
-	level, err := collectionmodels.GetAllLevels(client, dbName, os.Getenv("MONGODB_COLLECTION_LEVEL"))
+	rules, err := scoring.LoadRules(client, dbName)
 	if err != nil {
 		return nil, err
 	}
 
-	toolList, err := collectionmodels.GetAllCreativeTools(client, dbName, os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"))
+	recordIDs := make([]primitive.ObjectID, 0, len(tasks))
+	for _, task := range tasks {
+		recordIDs = append(recordIDs, task.ID)
+	}
+	disputes, err := collectionmodels.GetOpenDisputesByRecords(client, dbName, os.Getenv("MONGODB_COLLECTION_DISPUTE"), recordIDs)
 	if err != nil {
 		return nil, err
 	}
 
 	var entries []TaskEntry
 	for _, task := range tasks {
-		taskPoint := GetPointByLevel(level, task.Team, task.Level)
-		factor, creativeProcessPoint := GetCreativeTaskFactor(toolList, task.Tool, task.Level, task.Team)
-		creativeTaskPoint := float64(taskPoint) * factor
-		basePoint := float64(taskPoint) - creativeTaskPoint
-		toolPointsT, toolPointsQ := buildToolPoints(toolList, task.Tool, task.Level, task.Team)
+		score := rules.ScoreTask(&task, identifier)
 
 		entries = append(entries, TaskEntry{
 			TaskName:             task.TaskName,
@@ -801,92 +335,25 @@ func GetTaskEntries(client *mongo.Client, dbName, collectionName string, identif
 			Team:                 task.Team,
 			Level:                task.Level,
 			Project:              task.Project,
-			PerformancePoint:     float64(taskPoint) + creativeProcessPoint,
-			ToolPointsT:          toolPointsT,
-			ToolPointsQ:          toolPointsQ,
-			ToolFactor:           factor,
-			CreativeProcessPoint: creativeProcessPoint,
-			CreativeTaskPoint:    creativeTaskPoint,
-			BasePoint:            basePoint,
+			PerformancePoint:     score.PerformancePoint,
+			ToolPointsT:          score.ToolPointsT,
+			ToolPointsQ:          score.ToolPointsQ,
+			ToolFactor:           score.ToolFactor,
+			CreativeProcessPoint: score.CreativeProcessPoint,
+			CreativeTaskPoint:    score.CreativeTaskPoint,
+			BasePoint:            score.BasePoint,
 			DoneDate:             task.DoneDate,
+			Source:               task.RecordSource(),
+			Share:                score.Share,
+			Contributors:         task.Contributors,
+			TaskID:               task.TaskID,
 		})
-	}
-
-	return entries, nil
-}
-
-func GetPerformancePointTotals(identifier string, tasks []collectionmodels.CompletedTask, level []collectionmodels.Level, toolList []collectionmodels.CreativeTool) PerformancePointTotal {
-	performancePointTotal := PerformancePointTotal{}
-
-	for _, task := range tasks {
-		var TaskPoint = GetPointByLevel(level, task.Team, task.Level)
-		factor, sum := GetCreativeTaskFactor(toolList, task.Tool, task.Level, task.Team)
-		var CreativeTaskPoint = float64(TaskPoint) * factor
-		var CreativeProcessPoint = sum
-		var BasePoint = float64(TaskPoint) - CreativeTaskPoint
-
-		var CreativePoint = CreativeTaskPoint + CreativeProcessPoint
-		var TotalPerformance = BasePoint + CreativePoint
-
-		performancePointTotal.TotalBasePoint += BasePoint
-		performancePointTotal.TotalCreativeProcessPoint += CreativeProcessPoint
-		performancePointTotal.TotalCreativeTaskPoint += CreativeTaskPoint
-		performancePointTotal.TotalPerformancePoint += TotalPerformance
-	}
-
-	performancePointTotal.Identifier = identifier
-	return performancePointTotal
-}
-
-func GetPointByLevel(levels []collectionmodels.Level, team string, level int) int {
-
-	for _, l := range levels {
-		if l.Team == team {
-			if level > 0 && level <= len(l.LevelPoint) {
-				return l.LevelPoint[level-1]
-			}
-		}
-	}
-	return level
-}
-
-func GetCreativeTaskFactor(tools []collectionmodels.CreativeTool, inUsed []int, level int, team string) (float64, float64) {
-
-	var toolForTeam []collectionmodels.CreativeTool
-	for _, t := range tools {
-		if t.Team == team {
-			toolForTeam = append(toolForTeam, t)
+		if dispute, ok := disputes[task.ID]; ok {
+			entries[len(entries)-1].OpenDispute = dispute.ID.Hex()
 		}
 	}
 
-	// remove all tool not inUsed by index
-	var filteredTools []collectionmodels.CreativeTool
-	for _, idx := range inUsed {
-		for _, t := range toolForTeam {
-			if t.Index == idx {
-				filteredTools = append(filteredTools, t)
-			}
-		}
-	}
-
-	var factor float64 = 1.0
-	var sum float64 = 0.0
-	for _, t := range filteredTools {
-		if t.Type == "t" {
-			if level > 0 && level <= len(t.Point) {
-				factor *= (1 - t.Point[level-1])
-			}
-		} else {
-			if len(t.Point) > 0 {
-				sum += t.Point[0]
-			}
-		}
-	}
-	if factor == 1.0 {
-		factor = 0.0
-	}
-
-	return factor, sum
+	return entries, nil
 }
 
 func splitByMonday(startDate, endDate time.Time) [][2]time.Time {
@@ -0,0 +1,173 @@
+package db_handler
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IdentityIndex maps every account of every member (see Member.IdentityKeys),
+// departed members included, to the member's Email.
+type IdentityIndex map[string]string
+
+// BuildIdentityIndex indexes the members' accounts. An account claimed by two
+// members is an error, since its tasks could go to either.
+func BuildIdentityIndex(members []*collectionmodels.Member) (IdentityIndex, error) {
+	index := IdentityIndex{}
+	for _, m := range members {
+		for _, key := range m.IdentityKeys() {
+			if owner, ok := index[key]; ok && owner != m.Email {
+				return nil, fmt.Errorf("%s belongs to both %s and %s", key, owner, m.Email)
+			}
+			index[key] = m.Email
+		}
+	}
+	return index, nil
+}
+
+// Resolve returns the member a tracker assignee belongs to, matching the tracker
+// account id first and the email second.
+func (ix IdentityIndex) Resolve(tracker, accountID, email string) (string, bool) {
+	if accountID != "" {
+		if member, ok := ix[collectionmodels.IdentityKey(tracker, accountID)]; ok {
+			return member, true
+		}
+	}
+	if email != "" {
+		if member, ok := ix[collectionmodels.IdentityKey(collectionmodels.IDENTITY_EMAIL, email)]; ok {
+			return member, true
+		}
+	}
+	return "", false
+}
+
+// Members are re-read at most this often when resolving assignees during a sync.
+const identityCacheTTL = time.Minute
+
+var identities struct {
+	sync.Mutex
+	index    IdentityIndex
+	loadedAt time.Time
+}
+
+// LoadIdentityIndex reads every member and indexes their accounts.
+func LoadIdentityIndex(client *mongo.Client, dbName, collName string) (IdentityIndex, error) {
+	members, err := collectionmodels.GetAllMembers(client, os.Getenv("MONGO_URI"), dbName, collName)
+	if err != nil {
+		return nil, err
+	}
+	return BuildIdentityIndex(members)
+}
+
+func cachedIdentityIndex() (IdentityIndex, error) {
+	identities.Lock()
+	defer identities.Unlock()
+	if identities.index != nil && time.Since(identities.loadedAt) < identityCacheTTL {
+		return identities.index, nil
+	}
+	index, err := LoadIdentityIndex(GetMongoClient(), os.Getenv("MONGODB_NAME"), os.Getenv("MONGODB_COLLECTION_STAFF_MEMBER"))
+	if err != nil {
+		return nil, err
+	}
+	identities.index, identities.loadedAt = index, time.Now()
+	return index, nil
+}
+
+// InvalidateIdentities drops the cached index after members changed.
+func InvalidateIdentities() {
+	identities.Lock()
+	defer identities.Unlock()
+	identities.index = nil
+}
+
+// ResolveAssignee is the tasksource.AssigneeResolver backed by the members
+// collection. An assignee matching no member keeps their tracker email, so
+// they show up in the unmatched assignees report.
+func ResolveAssignee(tracker, accountID, email string) string {
+	index, err := cachedIdentityIndex()
+	if err != nil {
+		log.Printf("Error loading member identities, keeping assignee %s: %v", email, err)
+		return email
+	}
+	if member, ok := index.Resolve(tracker, accountID, email); ok {
+		return member
+	}
+	return email
+}
+
+// UnmatchedAssignee is an assignee id stored on completed tasks that is not a
+// member's Email, so its points count for nobody.
+type UnmatchedAssignee struct {
+	AssigneeID string `json:"assignee_id"`
+	// Member is who the id belongs to through their identities, when known; the
+	// tasks were stored before the identity was added and can be moved to them.
+	Member string          `json:"member,omitempty"`
+	Tasks  []UnmatchedTask `json:"tasks"`
+}
+
+type UnmatchedTask struct {
+	TaskID   string    `json:"task_id"`
+	TaskName string    `json:"task_name"`
+	Team     string    `json:"team"`
+	Source   string    `json:"source"`
+	DoneDate time.Time `json:"done_date"`
+}
+
+// GetUnmatchedAssignees lists, per assignee, the live tasks done in
+// [startDate, endDate] whose assignee or a contributor is not a member.
+func GetUnmatchedAssignees(client *mongo.Client, dbName, collectionName, memberCollName string, startDate, endDate time.Time) ([]UnmatchedAssignee, error) {
+	members, err := collectionmodels.GetAllMembers(client, os.Getenv("MONGO_URI"), dbName, memberCollName)
+	if err != nil {
+		return nil, err
+	}
+	index, err := BuildIdentityIndex(members)
+	if err != nil {
+		return nil, err
+	}
+	known := make([]string, 0, len(members))
+	isMember := map[string]bool{}
+	for _, m := range members {
+		known = append(known, m.Email)
+		isMember[m.Email] = true
+	}
+
+	tasks, err := collectionmodels.GetCompletedTasksWithUnknownAssignees(client, dbName, collectionName, known, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	byAssignee := map[string]*UnmatchedAssignee{}
+	add := func(assigneeID string, task *collectionmodels.CompletedTask) {
+		if isMember[assigneeID] {
+			return
+		}
+		u, ok := byAssignee[assigneeID]
+		if !ok {
+			u = &UnmatchedAssignee{AssigneeID: assigneeID, Tasks: []UnmatchedTask{}}
+			u.Member, _ = index.Resolve(task.RecordSource(), "", assigneeID)
+			byAssignee[assigneeID] = u
+		}
+		u.Tasks = append(u.Tasks, UnmatchedTask{TaskID: task.TaskID, TaskName: task.TaskName, Team: task.Team, Source: task.RecordSource(), DoneDate: task.DoneDate})
+	}
+	for i := range tasks {
+		add(tasks[i].AssigneeID, &tasks[i])
+		for _, c := range tasks[i].Contributors {
+			if c.AssigneeID != tasks[i].AssigneeID {
+				add(c.AssigneeID, &tasks[i])
+			}
+		}
+	}
+
+	results := make([]UnmatchedAssignee, 0, len(byAssignee))
+	for _, u := range byAssignee {
+		results = append(results, *u)
+	}
+	sort.Slice(results, func(i, j int) bool { return len(results[i].Tasks) > len(results[j].Tasks) })
+	return results, nil
+}
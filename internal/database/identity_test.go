@@ -0,0 +1,85 @@
+package db_handler
+
+import (
+	"testing"
+	"time"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+)
+
+func member(email string, identities ...collectionmodels.Identity) *collectionmodels.Member {
+	return &collectionmodels.Member{Email: email, Identities: identities}
+}
+
+func identity(kind, value string) collectionmodels.Identity {
+	return collectionmodels.Identity{Kind: kind, Value: value}
+}
+
+func TestIdentityIndexResolve(t *testing.T) {
+	left := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	departed := member("old@x.com", identity(collectionmodels.IDENTITY_CLICKUP, "42"))
+	departed.LeftAt = &left
+	index, err := BuildIdentityIndex([]*collectionmodels.Member{
+		member("a@x.com", identity(collectionmodels.IDENTITY_CLICKUP, "1"), identity(collectionmodels.IDENTITY_EMAIL, "A.Personal@gmail.com")),
+		member("b@x.com", identity(collectionmodels.IDENTITY_CLICKUP, "2"), identity(collectionmodels.IDENTITY_ASANA, "1")),
+		departed,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		tracker   string
+		accountID string
+		email     string
+		want      string
+	}{
+		{name: "account id before email", tracker: collectionmodels.IDENTITY_CLICKUP, accountID: "2", email: "a@x.com", want: "b@x.com"},
+		{name: "unknown account falls back to email", tracker: collectionmodels.IDENTITY_CLICKUP, accountID: "99", email: "a@x.com", want: "a@x.com"},
+		{name: "account ids are per tracker", tracker: collectionmodels.IDENTITY_ASANA, accountID: "1", want: "b@x.com"},
+		{name: "alias email", tracker: collectionmodels.IDENTITY_CLICKUP, email: " a.personal@GMAIL.com", want: "a@x.com"},
+		{name: "work email in another case", tracker: collectionmodels.IDENTITY_ASANA, email: "B@X.com", want: "b@x.com"},
+		{name: "departed member", tracker: collectionmodels.IDENTITY_CLICKUP, accountID: "42", email: "someone@gmail.com", want: "old@x.com"},
+		{name: "no match", tracker: collectionmodels.IDENTITY_CLICKUP, accountID: "7", email: "c@x.com"},
+	}
+	for _, tt := range tests {
+		got, ok := index.Resolve(tt.tracker, tt.accountID, tt.email)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("%s: Resolve = %q, %v; want %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestBuildIdentityIndexConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []*collectionmodels.Member
+		wantErr bool
+	}{
+		{
+			name:    "account claimed by two members",
+			members: []*collectionmodels.Member{member("a@x.com", identity(collectionmodels.IDENTITY_CLICKUP, "1")), member("b@x.com", identity(collectionmodels.IDENTITY_CLICKUP, "1"))},
+			wantErr: true,
+		},
+		{
+			name:    "alias that is another member's email",
+			members: []*collectionmodels.Member{member("a@x.com"), member("b@x.com", identity(collectionmodels.IDENTITY_EMAIL, "A@x.com"))},
+			wantErr: true,
+		},
+		{
+			name:    "member listing their own email as an alias",
+			members: []*collectionmodels.Member{member("a@x.com", identity(collectionmodels.IDENTITY_EMAIL, "A@X.com"))},
+		},
+		{
+			name:    "same id on two trackers",
+			members: []*collectionmodels.Member{member("a@x.com", identity(collectionmodels.IDENTITY_CLICKUP, "1")), member("b@x.com", identity(collectionmodels.IDENTITY_ASANA, "1"))},
+		},
+	}
+	for _, tt := range tests {
+		_, err := BuildIdentityIndex(tt.members)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package db_handler
+
+import (
+	"fmt"
+	"time"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/scoring"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RuleChange is a proposed new level table and/or tool points for a team,
+// starting at EffectiveFrom.
+type RuleChange struct {
+	Team          string       `json:"team"`
+	EffectiveFrom time.Time    `json:"effective_from"`
+	LevelPoint    []int        `json:"level_point,omitempty"`
+	Tools         []ToolChange `json:"tools,omitempty"`
+}
+
+// ToolChange is the new type and points of a tool; Index is only used for a tool
+// that does not exist yet.
+type ToolChange struct {
+	ToolName string    `json:"tool_name"`
+	Type     string    `json:"type"`
+	Point    []float64 `json:"point"`
+	Index    int       `json:"index"`
+}
+
+// RulePreviewWeek compares a team's week as scored now and after the change.
+type RulePreviewWeek struct {
+	StartDate  time.Time             `json:"start_date"`
+	EndDate    time.Time             `json:"end_date"`
+	Current    PerformancePointTotal `json:"current"`
+	Proposed   PerformancePointTotal `json:"proposed"`
+	Difference float64               `json:"difference"`
+}
+
+// Apply returns the rules as they would be once the change took effect.
+func (c *RuleChange) Apply(rules *scoring.Rules) (*scoring.Rules, error) {
+	levels, tools := rules.Levels, rules.Tools
+	var err error
+	if len(c.LevelPoint) > 0 {
+		levels, err = collectionmodels.WithLevelVersion(levels, c.Team, c.LevelPoint, c.EffectiveFrom)
+		if err != nil {
+			return nil, fmt.Errorf("level table: %w", err)
+		}
+	}
+	for _, t := range c.Tools {
+		tools, err = collectionmodels.WithCreativeToolVersion(tools, collectionmodels.CreativeTool{
+			Team:     c.Team,
+			ToolName: t.ToolName,
+			Type:     t.Type,
+			Point:    t.Point,
+			Index:    t.Index,
+		}, c.EffectiveFrom)
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: %w", t.ToolName, err)
+		}
+	}
+	return &scoring.Rules{Levels: levels, Tools: tools}, nil
+}
+
+// PreviewRuleChange scores the team's weeks in [startDate, endDate] with the
+// current rules and with the change applied. Nothing is written.
+func PreviewRuleChange(client *mongo.Client, dbName, collectionName string, change *RuleChange, startDate, endDate time.Time) ([]RulePreviewWeek, error) {
+	rules, err := scoring.LoadRules(client, dbName)
+	if err != nil {
+		return nil, err
+	}
+	proposed, err := change.Apply(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	var weeks []RulePreviewWeek
+	for _, dateRange := range splitByMonday(startDate, endDate) {
+		tasks, err := collectionmodels.GetCompletedTasksByDateRange(client, dbName, collectionName, true, change.Team, dateRange[0], dateRange[1])
+		if err != nil {
+			return nil, err
+		}
+		if len(tasks) == 0 {
+			continue
+		}
+		week := RulePreviewWeek{
+			StartDate: dateRange[0],
+			EndDate:   dateRange[1],
+			Current:   rules.Total(change.Team, tasks),
+			Proposed:  proposed.Total(change.Team, tasks),
+		}
+		week.Difference = week.Proposed.TotalPerformancePoint - week.Current.TotalPerformancePoint
+		weeks = append(weeks, week)
+	}
+	return weeks, nil
+}
@@ -0,0 +1,161 @@
+package db_handler
+
+import (
+	"sort"
+	"time"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/scoring"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CandidateRules are level tables and tool sets to score with instead of the
+// stored ones. A team listed here uses only its candidate table / tools, for every
+// week; other teams keep their stored, versioned rules.
+type CandidateRules struct {
+	Levels []CandidateLevel `json:"levels,omitempty"`
+	Tools  []CandidateTool  `json:"tools,omitempty"`
+}
+
+type CandidateLevel struct {
+	Team       string `json:"team"`
+	LevelPoint []int  `json:"level_point"`
+}
+
+type CandidateTool struct {
+	Team string `json:"team"`
+	ToolChange
+}
+
+// Apply replaces the stored rules of the candidate teams.
+func (c *CandidateRules) Apply(rules *scoring.Rules) *scoring.Rules {
+	levelTeams := map[string]bool{}
+	for _, l := range c.Levels {
+		levelTeams[l.Team] = true
+	}
+	toolTeams := map[string]bool{}
+	for _, t := range c.Tools {
+		toolTeams[t.Team] = true
+	}
+
+	var simLevels []collectionmodels.Level
+	for _, l := range rules.Levels {
+		if !levelTeams[l.Team] {
+			simLevels = append(simLevels, l)
+		}
+	}
+	for _, l := range c.Levels {
+		simLevels = append(simLevels, collectionmodels.Level{Team: l.Team, LevelPoint: l.LevelPoint})
+	}
+
+	var simTools []collectionmodels.CreativeTool
+	for _, t := range rules.Tools {
+		if !toolTeams[t.Team] {
+			simTools = append(simTools, t)
+		}
+	}
+	for _, t := range c.Tools {
+		simTools = append(simTools, collectionmodels.CreativeTool{Team: t.Team, ToolName: t.ToolName, Type: t.Type, Point: t.Point, Index: t.Index})
+	}
+	return &scoring.Rules{Levels: simLevels, Tools: simTools}
+}
+
+// SimulationRow compares a member's points for a period with the stored rules
+// and with the candidate rules.
+type SimulationRow struct {
+	Member     string                `json:"member"`
+	StartDate  time.Time             `json:"start_date"`
+	EndDate    time.Time             `json:"end_date"`
+	Current    PerformancePointTotal `json:"current"`
+	Simulated  PerformancePointTotal `json:"simulated"`
+	Difference float64               `json:"difference"`
+}
+
+type SimulationResult struct {
+	// Weeks has a row per member and week with tasks; Members sums each member's weeks.
+	Weeks   []SimulationRow `json:"weeks"`
+	Members []SimulationRow `json:"members"`
+}
+
+// SimulateScoring scores the completed tasks of the given teams and members in
+// [startDate, endDate] with the stored rules and with the candidate rules, per
+// member and week. Tasks of a team are credited to each of their contributors.
+// Nothing is written.
+func SimulateScoring(client *mongo.Client, dbName, collectionName string, rules *CandidateRules, teams, members []string, startDate, endDate time.Time) (*SimulationResult, error) {
+	current, err := scoring.LoadRules(client, dbName)
+	if err != nil {
+		return nil, err
+	}
+	simulated := rules.Apply(current)
+
+	// Collect each member's tasks once, even when listed both directly and through a team.
+	tasksByMember := map[string]map[string]collectionmodels.CompletedTask{}
+	addTask := func(member string, task collectionmodels.CompletedTask) {
+		if tasksByMember[member] == nil {
+			tasksByMember[member] = map[string]collectionmodels.CompletedTask{}
+		}
+		tasksByMember[member][task.ID.Hex()] = task
+	}
+	for _, team := range teams {
+		tasks, err := collectionmodels.GetCompletedTasksByDateRange(client, dbName, collectionName, true, team, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			if len(task.Contributors) == 0 {
+				addTask(task.AssigneeID, task)
+			}
+			for _, c := range task.Contributors {
+				addTask(c.AssigneeID, task)
+			}
+		}
+	}
+	for _, member := range members {
+		tasks, err := collectionmodels.GetCompletedTasksByDateRange(client, dbName, collectionName, false, member, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			addTask(member, task)
+		}
+	}
+
+	names := make([]string, 0, len(tasksByMember))
+	for member := range tasksByMember {
+		names = append(names, member)
+	}
+	sort.Strings(names)
+
+	result := &SimulationResult{Weeks: []SimulationRow{}, Members: []SimulationRow{}}
+	weeks := splitByMonday(startDate, endDate)
+	for _, member := range names {
+		total := SimulationRow{Member: member, StartDate: startDate, EndDate: endDate, Current: PerformancePointTotal{Identifier: member}, Simulated: PerformancePointTotal{Identifier: member}}
+		for _, week := range weeks {
+			var weekTasks []collectionmodels.CompletedTask
+			for _, task := range tasksByMember[member] {
+				if !task.DoneDate.Before(week[0]) && !task.DoneDate.After(week[1]) {
+					weekTasks = append(weekTasks, task)
+				}
+			}
+			if len(weekTasks) == 0 {
+				continue
+			}
+			row := SimulationRow{
+				Member:    member,
+				StartDate: week[0],
+				EndDate:   week[1],
+				Current:   current.Total(member, weekTasks),
+				Simulated: simulated.Total(member, weekTasks),
+			}
+			row.Difference = row.Simulated.TotalPerformancePoint - row.Current.TotalPerformancePoint
+			result.Weeks = append(result.Weeks, row)
+
+			total.Current.AddTotals(row.Current)
+			total.Simulated.AddTotals(row.Simulated)
+			total.Difference += row.Difference
+		}
+		result.Members = append(result.Members, total)
+	}
+	return result, nil
+}
@@ -0,0 +1,259 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// allowedClockSkew tolerates small clock differences between us and the issuer.
+const allowedClockSkew = 2 * time.Minute
+
+type Claims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	Expiry        int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified any      `json:"email_verified"`
+	HostedDomain  string   `json:"hd"`
+	Name          string   `json:"name"`
+}
+
+// IsEmailVerified accepts both boolean and string encodings; some issuers send "true".
+func (c *Claims) IsEmailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	default:
+		return false
+	}
+}
+
+// audience handles "aud" being either a string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type keySet struct {
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// VerifyIDToken checks signature, issuer, audience, expiry and nonce of a raw ID token.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, expectedNonce string) (*Claims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed id token")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed id token header: %w", err)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("malformed id token header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed id token signature: %w", err)
+	}
+
+	key, err := p.publicKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	switch header.Alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("id token alg RS256 does not match key type")
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, errors.New("invalid id token signature")
+		}
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return nil, errors.New("id token alg ES256 does not match key type")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, digest[:], r, s) {
+			return nil, errors.New("invalid id token signature")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported id token alg %q", header.Alg)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed id token payload: %w", err)
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed id token payload: %w", err)
+	}
+
+	now := time.Now()
+	if strings.TrimRight(claims.Issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("unexpected id token issuer %q", claims.Issuer)
+	}
+	if !containsString(claims.Audience, p.config.ClientID) {
+		return nil, errors.New("id token audience does not include client id")
+	}
+	if claims.Expiry == 0 || now.After(time.Unix(claims.Expiry, 0).Add(allowedClockSkew)) {
+		return nil, errors.New("id token expired")
+	}
+	if claims.IssuedAt != 0 && time.Unix(claims.IssuedAt, 0).After(now.Add(allowedClockSkew)) {
+		return nil, errors.New("id token issued in the future")
+	}
+	if expectedNonce != "" && claims.Nonce != expectedNonce {
+		return nil, errors.New("id token nonce mismatch")
+	}
+	if p.config.AllowedDomain != "" && !strings.EqualFold(claims.HostedDomain, p.config.AllowedDomain) {
+		return nil, fmt.Errorf("id token hosted domain %q is not allowed", claims.HostedDomain)
+	}
+	return &claims, nil
+}
+
+// publicKey returns the key for kid, refetching the JWKS once if the kid is unknown
+// (issuers rotate keys without notice).
+func (p *Provider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	cached := p.keys
+	p.mu.Unlock()
+	if cached != nil {
+		if key, ok := lookupKey(cached, kid); ok {
+			return key, nil
+		}
+		if time.Since(cached.fetchedAt) < time.Minute {
+			return nil, fmt.Errorf("no signing key for kid %q", kid)
+		}
+	}
+
+	var raw struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, doc.JWKSURI, &raw); err != nil {
+		return nil, fmt.Errorf("fetching jwks failed: %w", err)
+	}
+	set := &keySet{keys: map[string]crypto.PublicKey{}, fetchedAt: time.Now()}
+	for _, jwk := range raw.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(jwk)
+		if err != nil {
+			fmt.Println("Skipping unusable JWK", jwk.Kid, ":", err)
+			continue
+		}
+		set.keys[jwk.Kid] = key
+	}
+	p.mu.Lock()
+	p.keys = set
+	p.mu.Unlock()
+
+	if key, ok := lookupKey(set, kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no signing key for kid %q", kid)
+}
+
+func lookupKey(set *keySet, kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(set.keys) == 1 {
+		for _, key := range set.keys {
+			return key, true
+		}
+	}
+	key, ok := set.keys[kid]
+	return key, ok
+}
+
+func parseJWK(jwk jsonWebKey) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
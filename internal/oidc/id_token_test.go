@@ -0,0 +1,146 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"performance-dashboard-backend/internal/oidc"
+	"performance-dashboard-backend/internal/oidc/oidctest"
+)
+
+func TestVerifyIDToken(t *testing.T) {
+	iss := oidctest.NewIssuer("dashboard")
+	defer iss.Close()
+	provider := oidc.NewProvider(oidc.Config{Issuer: iss.URL, ClientID: "dashboard", RedirectURL: "http://localhost/auth/oidc/callback"})
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		nonce   string
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			token: func() string { return iss.Sign(iss.Claims("a@example.com", "n1")) },
+			nonce: "n1",
+		},
+		{
+			name:    "bad signature",
+			token:   func() string { return oidctest.SignWith(otherKey, oidctest.KeyID, iss.Claims("a@example.com", "n1")) },
+			nonce:   "n1",
+			wantErr: "invalid id token signature",
+		},
+		{
+			name: "tampered payload",
+			token: func() string {
+				good := strings.Split(iss.Sign(iss.Claims("a@example.com", "n1")), ".")
+				forged := strings.Split(iss.Sign(iss.Claims("admin@example.com", "n1")), ".")
+				return good[0] + "." + forged[1] + "." + good[2]
+			},
+			nonce:   "n1",
+			wantErr: "invalid id token signature",
+		},
+		{
+			name:    "unknown key id",
+			token:   func() string { return oidctest.SignWith(iss.Key, "other", iss.Claims("a@example.com", "n1")) },
+			nonce:   "n1",
+			wantErr: "no signing key",
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				c := iss.Claims("a@example.com", "n1")
+				c["aud"] = []string{"someone-else"}
+				return iss.Sign(c)
+			},
+			nonce:   "n1",
+			wantErr: "audience",
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				c := iss.Claims("a@example.com", "n1")
+				c["iss"] = "https://evil.example.com"
+				return iss.Sign(c)
+			},
+			nonce:   "n1",
+			wantErr: "issuer",
+		},
+		{
+			name: "expired",
+			token: func() string {
+				c := iss.Claims("a@example.com", "n1")
+				c["exp"] = time.Now().Add(-time.Hour).Unix()
+				return iss.Sign(c)
+			},
+			nonce:   "n1",
+			wantErr: "expired",
+		},
+		{
+			name: "issued in the future",
+			token: func() string {
+				c := iss.Claims("a@example.com", "n1")
+				c["iat"] = time.Now().Add(time.Hour).Unix()
+				return iss.Sign(c)
+			},
+			nonce:   "n1",
+			wantErr: "future",
+		},
+		{
+			name:    "nonce mismatch",
+			token:   func() string { return iss.Sign(iss.Claims("a@example.com", "n1")) },
+			nonce:   "n2",
+			wantErr: "nonce",
+		},
+		{
+			name:    "malformed",
+			token:   func() string { return "not-a-jwt" },
+			wantErr: "malformed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := provider.VerifyIDToken(context.Background(), tt.token(), tt.nonce)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if claims.Email != "a@example.com" || !claims.IsEmailVerified() {
+					t.Fatalf("claims %+v", claims)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExchange(t *testing.T) {
+	iss := oidctest.NewIssuer("dashboard")
+	defer iss.Close()
+	provider := oidc.NewProvider(oidc.Config{Issuer: iss.URL, ClientID: "dashboard", RedirectURL: "http://localhost/auth/oidc/callback"})
+
+	idToken := iss.Sign(iss.Claims("a@example.com", "n1"))
+	code := iss.IssueCode(idToken)
+	token, err := provider.Exchange(context.Background(), code, "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.IDToken != idToken {
+		t.Fatalf("id token %q, want %q", token.IDToken, idToken)
+	}
+	if _, err := provider.Exchange(context.Background(), code, "verifier"); err == nil {
+		t.Fatal("a code was exchanged twice")
+	}
+}
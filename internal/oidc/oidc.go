@@ -0,0 +1,205 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config describes the OpenID Connect relying party. Issuer may point at Google
+// (https://accounts.google.com) or at any local stand-in issuer for testing.
+type Config struct {
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+	JWKSURL       string // optional override of the discovered jwks_uri
+	AllowedDomain string // optional hosted domain (Google Workspace "hd" claim) restriction
+}
+
+func ConfigFromEnv() Config {
+	return Config{
+		Issuer:        strings.TrimRight(os.Getenv("OIDC_ISSUER"), "/"),
+		ClientID:      os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:  os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:   os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:        []string{"openid", "email", "profile"},
+		JWKSURL:       os.Getenv("OIDC_JWKS_URL"),
+		AllowedDomain: os.Getenv("OIDC_ALLOWED_DOMAIN"),
+	}
+}
+
+func (c Config) Enabled() bool {
+	return c.Issuer != "" && c.ClientID != "" && c.RedirectURL != ""
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Provider talks to one issuer: discovery, code exchange and ID-token verification.
+type Provider struct {
+	config     Config
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      *keySet
+}
+
+func NewProvider(config Config) *Provider {
+	return &Provider{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *Provider) Config() Config {
+	return p.config
+}
+
+func (p *Provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	var doc discoveryDocument
+	if err := p.getJSON(ctx, p.config.Issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("oidc discovery failed: %w", err)
+	}
+	if strings.TrimRight(doc.Issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("oidc discovery issuer mismatch: got %s, want %s", doc.Issuer, p.config.Issuer)
+	}
+	if p.config.JWKSURL != "" {
+		doc.JWKSURI = p.config.JWKSURL
+	}
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+// AuthCodeURL builds the authorization request for the code flow with PKCE (S256).
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	q := neturl.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.config.ClientID)
+	q.Set("redirect_uri", p.config.RedirectURL)
+	q.Set("scope", strings.Join(p.config.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", S256Challenge(codeVerifier))
+	q.Set("code_challenge_method", "S256")
+	if p.config.AllowedDomain != "" {
+		q.Set("hd", p.config.AllowedDomain)
+	}
+	sep := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return doc.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange trades an authorization code for tokens at the token endpoint.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*TokenResponse, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := neturl.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("oidc token exchange failed (status=%d): %s", resp.StatusCode, string(body))
+	}
+	var token TokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("error unmarshalling token response: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("oidc token response has no id_token")
+	}
+	return &token, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("GET %s failed (status=%d): %s", url, resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, out)
+}
+
+// NewRandomString returns a URL-safe random value for state, nonce and PKCE verifiers.
+func NewRandomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func S256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,144 @@
+// Package oidctest runs a local stand-in OpenID Connect issuer for tests: it
+// serves discovery, a JWKS and a token endpoint, and signs ID tokens with its
+// own RSA key.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+const KeyID = "test-key"
+
+// Issuer is a running stand-in issuer. Codes handed out by IssueCode are
+// exchanged once at the token endpoint for the ID token they were issued with.
+type Issuer struct {
+	URL      string
+	ClientID string
+	Key      *rsa.PrivateKey
+
+	server *httptest.Server
+	mu     sync.Mutex
+	codes  map[string]string
+}
+
+// NewIssuer starts an issuer for clientID; Close stops it.
+func NewIssuer(clientID string) *Issuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	iss := &Issuer{ClientID: clientID, Key: key, codes: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", iss.handleDiscovery)
+	mux.HandleFunc("/jwks", iss.handleJWKS)
+	mux.HandleFunc("/token", iss.handleToken)
+	iss.server = httptest.NewServer(mux)
+	iss.URL = iss.server.URL
+	return iss
+}
+
+func (iss *Issuer) Close() {
+	iss.server.Close()
+}
+
+// Claims are valid ID token claims for email and nonce; tests edit them to
+// produce bad tokens.
+func (iss *Issuer) Claims(email, nonce string) map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss":            iss.URL,
+		"sub":            "sub-" + email,
+		"aud":            iss.ClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          email,
+		"email_verified": true,
+	}
+}
+
+// Sign returns an RS256 ID token with claims signed by the issuer's key.
+func (iss *Issuer) Sign(claims map[string]interface{}) string {
+	return SignWith(iss.Key, KeyID, claims)
+}
+
+// SignWith signs claims with any key under kid, e.g. to forge a bad signature.
+func SignWith(key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// IssueCode registers an authorization code the token endpoint exchanges for idToken.
+func (iss *Issuer) IssueCode(idToken string) string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	code := base64.RawURLEncoding.EncodeToString(buf)
+	iss.mu.Lock()
+	iss.codes[code] = idToken
+	iss.mu.Unlock()
+	return code
+}
+
+func (iss *Issuer) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]string{
+		"issuer":                 iss.URL,
+		"authorization_endpoint": iss.URL + "/authorize",
+		"token_endpoint":         iss.URL + "/token",
+		"jwks_uri":               iss.URL + "/jwks",
+	})
+}
+
+func (iss *Issuer) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	pub := iss.Key.PublicKey
+	writeJSON(w, map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": KeyID,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+func (iss *Issuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+		return
+	}
+	if r.PostForm.Get("client_id") != iss.ClientID || r.PostForm.Get("code_verifier") == "" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	code := r.PostForm.Get("code")
+	iss.mu.Lock()
+	idToken, ok := iss.codes[code]
+	delete(iss.codes, code)
+	iss.mu.Unlock()
+	if !ok {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"access_token": "access-" + code, "id_token": idToken, "token_type": "Bearer", "expires_in": 3600})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,52 @@
+package oidc
+
+import (
+	"sync"
+	"time"
+)
+
+// PendingLogin is what we remember between redirecting to the issuer and the callback.
+type PendingLogin struct {
+	Nonce        string
+	CodeVerifier string
+	CreatedAt    time.Time
+}
+
+// StateStore holds pending logins keyed by the OAuth state parameter.
+// Entries are single use and expire after TTL.
+type StateStore struct {
+	mu      sync.Mutex
+	pending map[string]PendingLogin
+	TTL     time.Duration
+}
+
+func NewStateStore(ttl time.Duration) *StateStore {
+	return &StateStore{pending: map[string]PendingLogin{}, TTL: ttl}
+}
+
+func (s *StateStore) Put(state string, login PendingLogin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, v := range s.pending {
+		if now.Sub(v.CreatedAt) > s.TTL {
+			delete(s.pending, k)
+		}
+	}
+	s.pending[state] = login
+}
+
+// Take returns and removes the pending login for state.
+func (s *StateStore) Take(state string) (PendingLogin, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	login, ok := s.pending[state]
+	if !ok {
+		return PendingLogin{}, false
+	}
+	delete(s.pending, state)
+	if time.Since(login.CreatedAt) > s.TTL {
+		return PendingLogin{}, false
+	}
+	return login, true
+}
@@ -0,0 +1,289 @@
+// Package scheduler runs named background jobs on cron schedules. Admins can list
+// the jobs, pause and resume them, change their schedule and trigger them on
+// demand; a job never runs twice at the same time.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	TRIGGER_SCHEDULE = "schedule"
+	TRIGGER_MANUAL   = "manual"
+
+	// SPEC_OFF registers a job without a schedule; it only runs when triggered.
+	SPEC_OFF = "off"
+)
+
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// JobFunc is the work of a job. TriggerFrom(ctx) tells a scheduled run from a manual one.
+type JobFunc func(ctx context.Context) error
+
+// Run is the outcome of one run of a job.
+type Run struct {
+	Trigger    string     `json:"trigger"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// JobStatus is what the admin API shows for a job.
+type JobStatus struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule"`
+	Paused      bool       `json:"paused"`
+	Running     bool       `json:"running"`
+	NextRun     *time.Time `json:"next_run,omitempty"`
+	LastRun     *Run       `json:"last_run,omitempty"`
+	// SkippedRuns counts scheduled runs dropped because the previous run was still going.
+	SkippedRuns int `json:"skipped_runs"`
+}
+
+type job struct {
+	name        string
+	description string
+	spec        string
+	fn          JobFunc
+	entryID     cron.EntryID
+	paused      bool
+	running     bool
+	lastRun     *Run
+	skipped     int
+}
+
+type Scheduler struct {
+	cron *cron.Cron
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+func New(loc *time.Location) *Scheduler {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Scheduler{
+		cron: cron.New(cron.WithLocation(loc)),
+		jobs: map[string]*job{},
+	}
+}
+
+// SpecFromEnv returns SCHEDULE_<NAME> (name upper-cased, dashes as underscores)
+// when set, otherwise def. "off" disables the schedule.
+func SpecFromEnv(name, def string) string {
+	key := "SCHEDULE_" + strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(name))
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
+
+// Register adds a job under a unique name. The schedule is SpecFromEnv(name, defaultSpec).
+func (s *Scheduler) Register(name, description, defaultSpec string, fn JobFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("job %s registered twice", name)
+	}
+	j := &job{name: name, description: description, fn: fn}
+	if err := s.schedule(j, SpecFromEnv(name, defaultSpec)); err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	s.jobs[name] = j
+	return nil
+}
+
+// schedule replaces the cron entry of j. Callers hold s.mu.
+func (s *Scheduler) schedule(j *job, spec string) error {
+	spec = strings.TrimSpace(spec)
+	var entryID cron.EntryID
+	if spec != "" && spec != SPEC_OFF {
+		var err error
+		entryID, err = s.cron.AddFunc(spec, func() { s.run(j.name, TRIGGER_SCHEDULE) })
+		if err != nil {
+			return fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+	} else {
+		spec = SPEC_OFF
+	}
+	if j.entryID != 0 {
+		s.cron.Remove(j.entryID)
+	}
+	j.entryID = entryID
+	j.spec = spec
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops scheduling; the returned context is done once running cron jobs finish.
+func (s *Scheduler) Stop() context.Context {
+	return s.cron.Stop()
+}
+
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		statuses = append(statuses, s.status(j))
+	}
+	sort.Slice(statuses, func(i, k int) bool { return statuses[i].Name < statuses[k].Name })
+	return statuses
+}
+
+func (s *Scheduler) Job(name string) (JobStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return JobStatus{}, ErrUnknownJob
+	}
+	return s.status(j), nil
+}
+
+func (s *Scheduler) status(j *job) JobStatus {
+	status := JobStatus{
+		Name:        j.name,
+		Description: j.description,
+		Schedule:    j.spec,
+		Paused:      j.paused,
+		Running:     j.running,
+		SkippedRuns: j.skipped,
+	}
+	if j.lastRun != nil {
+		last := *j.lastRun
+		status.LastRun = &last
+	}
+	if j.entryID != 0 && !j.paused {
+		if next := s.cron.Entry(j.entryID).Next; !next.IsZero() {
+			status.NextRun = &next
+		}
+	}
+	return status
+}
+
+// Pause stops scheduled runs of a job; it can still be triggered manually.
+func (s *Scheduler) Pause(name string) error {
+	return s.update(name, func(j *job) error {
+		j.paused = true
+		return nil
+	})
+}
+
+func (s *Scheduler) Resume(name string) error {
+	return s.update(name, func(j *job) error {
+		j.paused = false
+		return nil
+	})
+}
+
+// Reschedule changes the cron expression of a job until the next restart.
+func (s *Scheduler) Reschedule(name, spec string) error {
+	return s.update(name, func(j *job) error {
+		return s.schedule(j, spec)
+	})
+}
+
+func (s *Scheduler) update(name string, fn func(j *job) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return ErrUnknownJob
+	}
+	return fn(j)
+}
+
+// Trigger starts a run of the job in the background, or returns ErrJobRunning.
+func (s *Scheduler) Trigger(name string) error {
+	j, err := s.begin(name, TRIGGER_MANUAL)
+	if err != nil {
+		return err
+	}
+	go s.execute(j, TRIGGER_MANUAL)
+	return nil
+}
+
+func (s *Scheduler) run(name, trigger string) {
+	j, err := s.begin(name, trigger)
+	if err != nil {
+		if err == ErrJobRunning {
+			fmt.Printf("Scheduler: %s is still running, skipping this run\n", name)
+		}
+		return
+	}
+	if j != nil {
+		s.execute(j, trigger)
+	}
+}
+
+// begin marks the job running. A paused job returns nil for scheduled runs.
+func (s *Scheduler) begin(name, trigger string) (*job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+	if trigger == TRIGGER_SCHEDULE && j.paused {
+		return nil, nil
+	}
+	if j.running {
+		if trigger == TRIGGER_SCHEDULE {
+			j.skipped++
+		}
+		return nil, ErrJobRunning
+	}
+	j.running = true
+	j.lastRun = &Run{Trigger: trigger, StartedAt: time.Now()}
+	return j, nil
+}
+
+func (s *Scheduler) execute(j *job, trigger string) {
+	ctx := context.WithValue(context.Background(), triggerKey{}, trigger)
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return j.fn(ctx)
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	finished := time.Now()
+	j.running = false
+	j.lastRun.FinishedAt = &finished
+	if err != nil {
+		j.lastRun.Error = err.Error()
+		fmt.Printf("Scheduler: %s failed: %v\n", j.name, err)
+	}
+}
+
+type triggerKey struct{}
+
+// TriggerFrom returns TRIGGER_SCHEDULE or TRIGGER_MANUAL for a job's context.
+func TriggerFrom(ctx context.Context) string {
+	if trigger, ok := ctx.Value(triggerKey{}).(string); ok {
+		return trigger
+	}
+	return TRIGGER_SCHEDULE
+}
@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitIdle waits for the job's current run to finish.
+func waitIdle(t *testing.T, s *Scheduler, name string) JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err := s.Job(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !status.Running {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s still running", name)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTriggerWhileRunning(t *testing.T) {
+	s := New(nil)
+	started, release := make(chan struct{}), make(chan struct{})
+	runs := 0
+	err := s.Register("sync", "", SPEC_OFF, func(ctx context.Context) error {
+		runs++
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Trigger("sync"); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := s.Trigger("sync"); !errors.Is(err, ErrJobRunning) {
+		t.Fatalf("second trigger: %v, want ErrJobRunning", err)
+	}
+	// A scheduled run that comes due meanwhile is skipped, not queued.
+	s.run("sync", TRIGGER_SCHEDULE)
+	if status, _ := s.Job("sync"); !status.Running || status.SkippedRuns != 1 {
+		t.Fatalf("status %+v, want running with one skipped run", status)
+	}
+
+	close(release)
+	status := waitIdle(t, s, "sync")
+	if runs != 1 || status.LastRun == nil || status.LastRun.FinishedAt == nil || status.LastRun.Trigger != TRIGGER_MANUAL {
+		t.Fatalf("after the run: %d runs, status %+v", runs, status)
+	}
+
+	// Once finished it can be triggered again.
+	release = make(chan struct{})
+	close(release)
+	if err := s.Trigger("sync"); err != nil {
+		t.Fatalf("trigger after the run finished: %v", err)
+	}
+	<-started
+	waitIdle(t, s, "sync")
+	if runs != 2 {
+		t.Fatalf("%d runs, want 2", runs)
+	}
+
+	if err := s.Trigger("nope"); !errors.Is(err, ErrUnknownJob) {
+		t.Fatalf("unknown job: %v", err)
+	}
+}
+
+func TestPausedJob(t *testing.T) {
+	s := New(nil)
+	var triggers []string
+	err := s.Register("sync", "", "@every 1h", func(ctx context.Context) error {
+		triggers = append(triggers, TriggerFrom(ctx))
+		return errors.New("tracker down")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	defer s.Stop()
+
+	if err := s.Pause("sync"); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := s.Job("sync"); !status.Paused || status.NextRun != nil {
+		t.Fatalf("paused job status %+v", status)
+	}
+	s.run("sync", TRIGGER_SCHEDULE)
+	if len(triggers) != 0 {
+		t.Fatalf("paused job ran on schedule: %v", triggers)
+	}
+
+	// A paused job still runs when triggered by hand.
+	if err := s.Trigger("sync"); err != nil {
+		t.Fatal(err)
+	}
+	status := waitIdle(t, s, "sync")
+	if len(triggers) != 1 || triggers[0] != TRIGGER_MANUAL {
+		t.Fatalf("runs %v, want one manual run", triggers)
+	}
+	if status.LastRun == nil || status.LastRun.Error != "tracker down" {
+		t.Fatalf("last run %+v, want the job's error", status.LastRun)
+	}
+
+	if err := s.Resume("sync"); err != nil {
+		t.Fatal(err)
+	}
+	s.run("sync", TRIGGER_SCHEDULE)
+	if len(triggers) != 2 || triggers[1] != TRIGGER_SCHEDULE {
+		t.Fatalf("runs %v, want a scheduled run after resuming", triggers)
+	}
+	if status, _ := s.Job("sync"); status.Paused || status.NextRun == nil {
+		t.Fatalf("resumed job status %+v", status)
+	}
+}
@@ -0,0 +1,203 @@
+// Package scoring is the one place performance points are computed. Every
+// endpoint that shows points, per task or summed, goes through ScoreTask.
+//
+// For a completed task of team T at difficulty level n, done in the week of DoneDate:
+//
+//   - Rules in force: only the level table and creative tools of team T whose
+//     effective period contains DoneDate are used.
+//   - Level point L: entry n (1-based) of the level table; the level itself when
+//     the team has no table or n is outside it.
+//   - Tools: each tool index selected on the task matches the team's tool with
+//     that index. Indexes matching no tool are ignored (see Score.UnmatchedTools).
+//   - Tool factor F: the product of (1 - p[n]) over the matched "t" tools that
+//     have a point for level n, p[n] being entry n of the tool's points. A
+//     product of exactly 1 counts as F = 0: no "t" tool applies, or every one
+//     that does has p[n] = 0.
+//   - Creative task point C = L × F, base point B = L − C.
+//   - Creative process point P: the sum of the first point of every matched
+//     tool of any other type ("q").
+//   - Performance point = B + C + P = L + P.
+//
+// A task shared between several assignees (CompletedTask.Contributors) gives
+// each contributor their share of every point; a team is credited the whole task.
+package scoring
+
+import (
+	"os"
+	"time"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const TOOL_TYPE_T = "t"
+
+// Rules are the level tables and creative tools tasks are scored with, every
+// version of them; ScoreTask picks the ones in force at each task's DoneDate.
+type Rules struct {
+	Levels []collectionmodels.Level
+	Tools  []collectionmodels.CreativeTool
+}
+
+// LoadRules reads every level table and tool version.
+func LoadRules(client *mongo.Client, dbName string) (*Rules, error) {
+	levels, err := collectionmodels.GetAllLevels(client, dbName, os.Getenv("MONGODB_COLLECTION_LEVEL"))
+	if err != nil {
+		return nil, err
+	}
+	tools, err := collectionmodels.GetAllCreativeTools(client, dbName, os.Getenv("MONGODB_COLLECTION_CREATIVE_TOOLS"))
+	if err != nil {
+		return nil, err
+	}
+	return &Rules{Levels: levels, Tools: tools}, nil
+}
+
+type ToolPoint struct {
+	Index int     `bson:"index"`
+	Point float64 `bson:"point"`
+}
+
+// Score is the points one identifier earns for one task, already multiplied by
+// Share, with what they were derived from.
+type Score struct {
+	// Level is the level table used and LevelIndex the entry read from it; the
+	// level itself is the point when there is no table (nil) or the level is
+	// outside it (LevelIndex -1).
+	Level      *collectionmodels.Level `json:"level_table"`
+	LevelIndex int                     `json:"level_index"`
+	LevelPoint int                     `json:"level_point"`
+	// ToolsT and ToolsQ are the matched tools; ToolPointsT holds p[n] of each "t"
+	// tool (its first point, not used in F, when it has none for the level),
+	// ToolPointsQ the first point of the others.
+	ToolsT         []collectionmodels.CreativeTool `json:"tools_t"`
+	ToolsQ         []collectionmodels.CreativeTool `json:"tools_q"`
+	ToolPointsT    []ToolPoint                     `json:"tool_points_t"`
+	ToolPointsQ    []ToolPoint                     `json:"tool_points_q"`
+	UnmatchedTools []int                           `json:"unmatched_tools"`
+	// FactorChain is the tool factor after each "t" tool that applied, in order.
+	FactorChain []FactorStep `json:"factor_chain"`
+
+	ToolFactor           float64 `json:"tool_factor"`
+	CreativeTaskPoint    float64 `json:"creative_task_point"`
+	CreativeProcessPoint float64 `json:"creative_process_point"`
+	BasePoint            float64 `json:"base_point"`
+	PerformancePoint     float64 `json:"performance_point"`
+	Share                float64 `json:"share"`
+}
+
+type FactorStep struct {
+	Index      int     `json:"index"`
+	ToolName   string  `json:"tool_name"`
+	Point      float64 `json:"point"`
+	Multiplier float64 `json:"multiplier"`
+	Factor     float64 `json:"factor"`
+}
+
+// Totals are the summed points of an identifier (member or team).
+type Totals struct {
+	TotalPerformancePoint     float64 `bson:"total_performance_point"`
+	TotalCreativeProcessPoint float64 `bson:"total_creative_process_point"`
+	TotalCreativeTaskPoint    float64 `bson:"total_creative_task_point"`
+	TotalBasePoint            float64 `bson:"total_base_point"`
+	Identifier                string  `bson:"identifier"`
+}
+
+func (t *Totals) Add(s Score) {
+	t.TotalPerformancePoint += s.PerformancePoint
+	t.TotalCreativeProcessPoint += s.CreativeProcessPoint
+	t.TotalCreativeTaskPoint += s.CreativeTaskPoint
+	t.TotalBasePoint += s.BasePoint
+}
+
+func (t *Totals) AddTotals(o Totals) {
+	t.TotalPerformancePoint += o.TotalPerformancePoint
+	t.TotalCreativeProcessPoint += o.TotalCreativeProcessPoint
+	t.TotalCreativeTaskPoint += o.TotalCreativeTaskPoint
+	t.TotalBasePoint += o.TotalBasePoint
+}
+
+// LevelTable is the level table of team in force at at, or nil.
+func (r *Rules) LevelTable(team string, at time.Time) *collectionmodels.Level {
+	for i := range r.Levels {
+		if r.Levels[i].Team == team && r.Levels[i].InForce(at) {
+			return &r.Levels[i]
+		}
+	}
+	return nil
+}
+
+// Tool is the tool of team with index in force at at, or nil.
+func (r *Rules) Tool(team string, index int, at time.Time) *collectionmodels.CreativeTool {
+	for i := range r.Tools {
+		if r.Tools[i].Team == team && r.Tools[i].Index == index && r.Tools[i].InForce(at) {
+			return &r.Tools[i]
+		}
+	}
+	return nil
+}
+
+// ScoreTask scores a task for identifier, a contributor or a team.
+func (r *Rules) ScoreTask(task *collectionmodels.CompletedTask, identifier string) Score {
+	at := task.DoneDate
+	score := Score{LevelIndex: -1, LevelPoint: task.Level, Share: task.CreditShare(identifier)}
+	if table := r.LevelTable(task.Team, at); table != nil {
+		score.Level = table
+		if task.Level > 0 && task.Level <= len(table.LevelPoint) {
+			score.LevelIndex = task.Level - 1
+			score.LevelPoint = table.LevelPoint[score.LevelIndex]
+		}
+	}
+
+	factor := 1.0
+	for _, idx := range task.Tool {
+		tool := r.Tool(task.Team, idx, at)
+		if tool == nil {
+			score.UnmatchedTools = append(score.UnmatchedTools, idx)
+			continue
+		}
+		if tool.Type == TOOL_TYPE_T {
+			point := 0.0
+			if task.Level > 0 && task.Level <= len(tool.Point) {
+				point = tool.Point[task.Level-1]
+				factor *= 1 - point
+				score.FactorChain = append(score.FactorChain, FactorStep{Index: idx, ToolName: tool.ToolName, Point: point, Multiplier: 1 - point, Factor: factor})
+			} else if len(tool.Point) > 0 {
+				point = tool.Point[0]
+			}
+			score.ToolsT = append(score.ToolsT, *tool)
+			score.ToolPointsT = append(score.ToolPointsT, ToolPoint{Index: idx, Point: point})
+			continue
+		}
+		point := 0.0
+		if len(tool.Point) > 0 {
+			point = tool.Point[0]
+		}
+		score.CreativeProcessPoint += point
+		score.ToolsQ = append(score.ToolsQ, *tool)
+		score.ToolPointsQ = append(score.ToolPointsQ, ToolPoint{Index: idx, Point: point})
+	}
+	if factor != 1.0 {
+		score.ToolFactor = factor
+	}
+
+	levelPoint := float64(score.LevelPoint)
+	score.CreativeTaskPoint = levelPoint * score.ToolFactor
+	score.BasePoint = levelPoint - score.CreativeTaskPoint
+	score.PerformancePoint = levelPoint + score.CreativeProcessPoint
+
+	score.CreativeTaskPoint *= score.Share
+	score.BasePoint *= score.Share
+	score.CreativeProcessPoint *= score.Share
+	score.PerformancePoint *= score.Share
+	return score
+}
+
+// Total sums the scores of tasks for identifier.
+func (r *Rules) Total(identifier string, tasks []collectionmodels.CompletedTask) Totals {
+	totals := Totals{Identifier: identifier}
+	for i := range tasks {
+		totals.Add(r.ScoreTask(&tasks[i], identifier))
+	}
+	return totals
+}
@@ -0,0 +1,142 @@
+package scoring
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+)
+
+// goldenCase is one task of testdata/golden.json with the points the
+// pre-scoring code (GetPointByLevel, GetCreativeTaskFactor, buildToolPoints)
+// gave it under testdata/rules.json. The file covers every team, level 0..6 and
+// subset of the team's tool indexes plus an unknown one, on a date before and
+// after the Art level table and Video tool were replaced.
+type goldenCase struct {
+	Team                 string       `json:"team"`
+	Level                int          `json:"level"`
+	Tool                 []int        `json:"tool"`
+	DoneDate             time.Time    `json:"done_date"`
+	LevelPoint           int          `json:"level_point"`
+	ToolFactor           float64      `json:"tool_factor"`
+	CreativeTaskPoint    float64      `json:"creative_task_point"`
+	CreativeProcessPoint float64      `json:"creative_process_point"`
+	BasePoint            float64      `json:"base_point"`
+	PerformancePoint     float64      `json:"performance_point"`
+	ToolPointsT          []goldenTool `json:"tool_points_t"`
+	ToolPointsQ          []goldenTool `json:"tool_points_q"`
+}
+
+type goldenTool struct {
+	Index int     `json:"index"`
+	Point float64 `json:"point"`
+}
+
+func loadJSON(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+}
+
+func loadTestRules(t *testing.T) *Rules {
+	var rules Rules
+	loadJSON(t, "testdata/rules.json", &rules)
+	return &rules
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func sameToolPoints(got []ToolPoint, want []goldenTool) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i].Index != want[i].Index || !near(got[i].Point, want[i].Point) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestScoreTaskGolden(t *testing.T) {
+	rules := loadTestRules(t)
+	var cases []goldenCase
+	loadJSON(t, "testdata/golden.json", &cases)
+	if len(cases) == 0 {
+		t.Fatal("no golden cases")
+	}
+
+	for _, c := range cases {
+		task := &collectionmodels.CompletedTask{Team: c.Team, Level: c.Level, Tool: c.Tool, DoneDate: c.DoneDate}
+		got := rules.ScoreTask(task, c.Team)
+		name := func() string {
+			b, _ := json.Marshal(struct {
+				Team  string
+				Level int
+				Tool  []int
+				Date  string
+			}{c.Team, c.Level, c.Tool, c.DoneDate.Format(time.DateOnly)})
+			return string(b)
+		}
+		if got.LevelPoint != c.LevelPoint {
+			t.Errorf("%s: level point %d, want %d", name(), got.LevelPoint, c.LevelPoint)
+		}
+		for _, f := range []struct {
+			field     string
+			got, want float64
+		}{
+			{"tool factor", got.ToolFactor, c.ToolFactor},
+			{"creative task point", got.CreativeTaskPoint, c.CreativeTaskPoint},
+			{"creative process point", got.CreativeProcessPoint, c.CreativeProcessPoint},
+			{"base point", got.BasePoint, c.BasePoint},
+			{"performance point", got.PerformancePoint, c.PerformancePoint},
+		} {
+			if !near(f.got, f.want) {
+				t.Errorf("%s: %s %v, want %v", name(), f.field, f.got, f.want)
+			}
+		}
+		if !sameToolPoints(got.ToolPointsT, c.ToolPointsT) {
+			t.Errorf("%s: t tool points %v, want %v", name(), got.ToolPointsT, c.ToolPointsT)
+		}
+		if !sameToolPoints(got.ToolPointsQ, c.ToolPointsQ) {
+			t.Errorf("%s: q tool points %v, want %v", name(), got.ToolPointsQ, c.ToolPointsQ)
+		}
+	}
+}
+
+func TestScoreTaskShare(t *testing.T) {
+	rules := loadTestRules(t)
+	task := &collectionmodels.CompletedTask{
+		AssigneeID: "a@example.com",
+		Team:       "Art",
+		Level:      3,
+		Tool:       []int{1, 3},
+		DoneDate:   time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC),
+		Contributors: []collectionmodels.Contributor{
+			{AssigneeID: "a@example.com", Share: 0.25},
+			{AssigneeID: "b@example.com", Share: 0.75},
+		},
+	}
+	whole := rules.ScoreTask(task, "Art")
+	part := rules.ScoreTask(task, "b@example.com")
+	if part.Share != 0.75 {
+		t.Fatalf("share %v, want 0.75", part.Share)
+	}
+	if !near(part.PerformancePoint, whole.PerformancePoint*0.75) || !near(part.BasePoint, whole.BasePoint*0.75) ||
+		!near(part.CreativeTaskPoint, whole.CreativeTaskPoint*0.75) || !near(part.CreativeProcessPoint, whole.CreativeProcessPoint*0.75) {
+		t.Errorf("share of %+v is %+v", whole, part)
+	}
+	if part.ToolFactor != whole.ToolFactor {
+		t.Errorf("tool factor %v changed with the share, want %v", part.ToolFactor, whole.ToolFactor)
+	}
+}
@@ -0,0 +1,79 @@
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps sessions in process memory. Intended for tests and local runs.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: map[string]Session{}}
+}
+
+func (m *MemoryStore) Create(s *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[s.Token] = *s
+	return nil
+}
+
+func (m *MemoryStore) Get(token string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.sessions[token]
+	if !ok || !time.Now().Before(s.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+func (m *MemoryStore) Touch(token string, lastSeen, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[token]
+	if !ok {
+		return ErrNotFound
+	}
+	s.LastSeenAt = lastSeen
+	s.ExpiresAt = expiresAt
+	m.sessions[token] = s
+	return nil
+}
+
+func (m *MemoryStore) Revoke(token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, token)
+	return nil
+}
+
+func (m *MemoryStore) RevokeByEmail(email string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for token, s := range m.sessions {
+		if s.Email == email {
+			delete(m.sessions, token)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MemoryStore) DeleteExpired(now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for token, s := range m.sessions {
+		if !now.Before(s.ExpiresAt) {
+			delete(m.sessions, token)
+			n++
+		}
+	}
+	return n, nil
+}
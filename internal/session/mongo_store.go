@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore persists sessions in a collection keyed by token. A TTL index on
+// expires_at lets MongoDB purge dead sessions even if the cleanup job is not running.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoStore(client *mongo.Client, dbName, collName string) (*MongoStore, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	collection := client.Database(dbName).Collection(collName)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "email", Value: 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MongoStore{collection: collection}, nil
+}
+
+func (m *MongoStore) Create(s *Session) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := m.collection.InsertOne(ctx, s)
+	return err
+}
+
+func (m *MongoStore) Get(token string) (*Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var s Session
+	err := m.collection.FindOne(ctx, bson.M{"_id": token, "expires_at": bson.M{"$gt": time.Now().UTC()}}).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (m *MongoStore) Touch(token string, lastSeen, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := m.collection.UpdateOne(ctx,
+		bson.M{"_id": token},
+		bson.M{"$set": bson.M{"last_seen_at": lastSeen, "expires_at": expiresAt}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *MongoStore) Revoke(token string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := m.collection.DeleteOne(ctx, bson.M{"_id": token})
+	return err
+}
+
+func (m *MongoStore) RevokeByEmail(email string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := m.collection.DeleteMany(ctx, bson.M{"email": email})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+func (m *MongoStore) DeleteExpired(now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := m.collection.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lte": now}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
@@ -0,0 +1,149 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	db "performance-dashboard-backend/internal/database"
+)
+
+// ErrNotFound is returned by a Store when the token is unknown or already expired.
+var ErrNotFound = errors.New("session not found")
+
+const (
+	defaultTTL    = 24 * time.Hour
+	defaultMaxAge = 30 * 24 * time.Hour
+	// touchInterval throttles sliding refresh writes so every request does not hit the store.
+	touchInterval = time.Minute
+)
+
+type Session struct {
+	Token      string         `bson:"_id" json:"-"`
+	Email      string         `bson:"email" json:"email"`
+	TeamRole   []*db.TeamRole `bson:"team_role" json:"team_role"`
+	CreatedAt  time.Time      `bson:"created_at" json:"created_at"`
+	LastSeenAt time.Time      `bson:"last_seen_at" json:"last_seen_at"`
+	ExpiresAt  time.Time      `bson:"expires_at" json:"expires_at"`
+}
+
+// Store persists sessions. Implementations must treat expired sessions as missing.
+type Store interface {
+	Create(s *Session) error
+	Get(token string) (*Session, error)
+	Touch(token string, lastSeen, expiresAt time.Time) error
+	Revoke(token string) error
+	RevokeByEmail(email string) (int64, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+// Manager issues and validates sessions on top of a Store.
+// TTL is the idle timeout (refreshed on every use), MaxAge caps the total lifetime.
+type Manager struct {
+	store  Store
+	TTL    time.Duration
+	MaxAge time.Duration
+	now    func() time.Time
+}
+
+func NewManager(store Store, ttl, maxAge time.Duration) *Manager {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	if maxAge <= 0 {
+		maxAge = defaultMaxAge
+	}
+	return &Manager{store: store, TTL: ttl, MaxAge: maxAge, now: time.Now}
+}
+
+// NewManagerFromEnv reads SESSION_TTL and SESSION_MAX_AGE (Go durations, e.g. "24h").
+func NewManagerFromEnv(store Store) *Manager {
+	return NewManager(store, parseDurationEnv("SESSION_TTL"), parseDurationEnv("SESSION_MAX_AGE"))
+}
+
+func parseDurationEnv(key string) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		fmt.Printf("Invalid %s=%q, using default: %v\n", key, v, err)
+		return 0
+	}
+	return d
+}
+
+func (m *Manager) Issue(email string, teamRoles []*db.TeamRole) (*Session, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	now := m.now().UTC()
+	s := &Session{
+		Token:      token,
+		Email:      email,
+		TeamRole:   teamRoles,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(m.TTL),
+	}
+	if err := m.store.Create(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Lookup returns the live session for token and slides its expiry forward.
+func (m *Manager) Lookup(token string) (*Session, bool) {
+	if token == "" {
+		return nil, false
+	}
+	s, err := m.store.Get(token)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			fmt.Println("Session lookup error:", err)
+		}
+		return nil, false
+	}
+	now := m.now().UTC()
+	if !now.Before(s.ExpiresAt) {
+		return nil, false
+	}
+	if now.Sub(s.LastSeenAt) >= touchInterval {
+		expiresAt := now.Add(m.TTL)
+		if hardLimit := s.CreatedAt.Add(m.MaxAge); expiresAt.After(hardLimit) {
+			expiresAt = hardLimit
+		}
+		if err := m.store.Touch(token, now, expiresAt); err != nil {
+			fmt.Println("Session refresh error:", err)
+		} else {
+			s.LastSeenAt = now
+			s.ExpiresAt = expiresAt
+		}
+	}
+	return s, true
+}
+
+func (m *Manager) Revoke(token string) error {
+	return m.store.Revoke(token)
+}
+
+func (m *Manager) RevokeAll(email string) (int64, error) {
+	return m.store.RevokeByEmail(email)
+}
+
+func (m *Manager) DeleteExpired() (int64, error) {
+	return m.store.DeleteExpired(m.now().UTC())
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,154 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+// clock is a settable Manager.now; it starts at the real time so the
+// MemoryStore, which checks expiry against time.Now, agrees with the Manager.
+type clock struct{ at time.Time }
+
+func (c *clock) now() time.Time            { return c.at }
+func (c *clock) advance(d time.Duration)   { c.at = c.at.Add(d) }
+func (c *clock) since(t0 time.Time) string { return c.at.Sub(t0).String() }
+
+func newTestManager(ttl, maxAge time.Duration) (*Manager, *clock) {
+	c := &clock{at: time.Now().UTC()}
+	m := NewManager(NewMemoryStore(), ttl, maxAge)
+	m.now = c.now
+	return m, c
+}
+
+func issue(t *testing.T, m *Manager, email string) *Session {
+	t.Helper()
+	s, err := m.Issue(email, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestLookupSlidesExpiry(t *testing.T) {
+	m, c := newTestManager(time.Hour, 24*time.Hour)
+	t0 := c.at
+	s := issue(t, m, "a@example.com")
+	if !s.ExpiresAt.Equal(t0.Add(time.Hour)) {
+		t.Fatalf("new session expires at %s, want TTL after issue", s.ExpiresAt)
+	}
+
+	c.advance(30 * time.Minute)
+	got, ok := m.Lookup(s.Token)
+	if !ok || !got.ExpiresAt.Equal(c.at.Add(time.Hour)) {
+		t.Fatalf("after %s: ok=%v expires %v, want renewed to an hour from now", c.since(t0), ok, got)
+	}
+	renewed := got.ExpiresAt
+
+	// Within touchInterval of the last refresh the expiry is not written again.
+	c.advance(30 * time.Second)
+	if got, ok := m.Lookup(s.Token); !ok || !got.ExpiresAt.Equal(renewed) {
+		t.Fatalf("after %s: ok=%v expires %v, want unchanged %s", c.since(t0), ok, got, renewed)
+	}
+
+	// Past the original expiry, but within the renewed one.
+	c.advance(50 * time.Minute)
+	if _, ok := m.Lookup(s.Token); !ok {
+		t.Fatalf("after %s: session expired despite being renewed", c.since(t0))
+	}
+
+	// Idle for longer than the TTL.
+	c.advance(time.Hour + time.Second)
+	if _, ok := m.Lookup(s.Token); ok {
+		t.Fatalf("after %s idle: session still valid", c.since(t0))
+	}
+}
+
+func TestLookupCapsAtMaxAge(t *testing.T) {
+	m, c := newTestManager(time.Hour, 2*time.Hour)
+	t0 := c.at
+	s := issue(t, m, "a@example.com")
+
+	for _, step := range []time.Duration{50 * time.Minute, 50 * time.Minute, 19 * time.Minute} {
+		c.advance(step)
+		got, ok := m.Lookup(s.Token)
+		if !ok {
+			t.Fatalf("after %s: session expired before MaxAge", c.since(t0))
+		}
+		if got.ExpiresAt.After(t0.Add(2 * time.Hour)) {
+			t.Fatalf("after %s: expiry %s renewed past MaxAge", c.since(t0), got.ExpiresAt)
+		}
+	}
+
+	c.advance(time.Minute)
+	if _, ok := m.Lookup(s.Token); ok {
+		t.Fatalf("after %s: session outlived MaxAge although in use", c.since(t0))
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	m, _ := newTestManager(time.Hour, 24*time.Hour)
+	first := issue(t, m, "a@example.com")
+	second := issue(t, m, "a@example.com")
+	other := issue(t, m, "b@example.com")
+
+	if err := m.Revoke(first.Token); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Lookup(first.Token); ok {
+		t.Error("revoked session still valid")
+	}
+	if _, ok := m.Lookup(second.Token); !ok {
+		t.Error("revoking one session ended another of the same user")
+	}
+
+	third := issue(t, m, "a@example.com")
+	n, err := m.RevokeAll("a@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("RevokeAll ended %d sessions, want 2", n)
+	}
+	for _, s := range []*Session{second, third} {
+		if _, ok := m.Lookup(s.Token); ok {
+			t.Errorf("session %s... survived RevokeAll", s.Token[:8])
+		}
+	}
+	if _, ok := m.Lookup(other.Token); !ok {
+		t.Error("RevokeAll ended another user's session")
+	}
+}
+
+func TestDeleteExpired(t *testing.T) {
+	m, c := newTestManager(time.Hour, 24*time.Hour)
+	stale := issue(t, m, "a@example.com")
+	c.advance(40 * time.Minute)
+	fresh := issue(t, m, "b@example.com")
+
+	if n, err := m.DeleteExpired(); err != nil || n != 0 {
+		t.Fatalf("DeleteExpired before any expiry: %d, %v", n, err)
+	}
+
+	c.advance(30 * time.Minute)
+	n, err := m.DeleteExpired()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("DeleteExpired removed %d sessions, want 1", n)
+	}
+	store := m.store.(*MemoryStore)
+	if _, ok := store.sessions[stale.Token]; ok {
+		t.Error("expired session kept")
+	}
+	if _, ok := m.Lookup(fresh.Token); !ok {
+		t.Error("live session deleted")
+	}
+}
+
+func TestLookupEmptyToken(t *testing.T) {
+	m, _ := newTestManager(time.Hour, 24*time.Hour)
+	if _, ok := m.Lookup(""); ok {
+		t.Error("empty token accepted")
+	}
+}
@@ -0,0 +1,331 @@
+// Package syncconfig describes which ClickUp spaces and tags feed each team's
+// completed tasks, and how the scored fields are read from a task. The config is
+// JSON; ${VAR} references are expanded from the environment so space ids stay in .env.
+package syncconfig
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	WEBHOOK_TASK    = "task"
+	WEBHOOK_CONCEPT = "concept"
+
+	TRACKER_CLICKUP = "clickup"
+	TRACKER_ASANA   = "asana"
+
+	// ASSIGNEE_FIRST credits the first assignee; ASSIGNEE_SECOND credits the second
+	// one when there are several (the first is usually the requester).
+	ASSIGNEE_FIRST  = "first"
+	ASSIGNEE_SECOND = "second"
+
+	// CREDIT_SINGLE gives the whole task to the assignee picked by the assignee rule.
+	// CREDIT_EQUAL splits it evenly between every assignee, CREDIT_WEIGHTED by the
+	// team's weights (primary first) and CREDIT_FIELD by the percentages in a custom field.
+	CREDIT_SINGLE   = "single"
+	CREDIT_EQUAL    = "equal"
+	CREDIT_WEIGHTED = "weighted"
+	CREDIT_FIELD    = "field"
+
+	defaultProjectField = "Game Name"
+	defaultTimezone     = "Asia/Ho_Chi_Minh"
+)
+
+//go:embed default_sync_config.json
+var defaultConfig []byte
+
+type Config struct {
+	Timezone string  `json:"timezone"`
+	Teams    []*Team `json:"teams"`
+
+	location *time.Location
+}
+
+type Team struct {
+	Team string `json:"team"`
+	// Webhook is the ClickUp webhook route that feeds this team: "task" (default) or "concept".
+	Webhook         string    `json:"webhook,omitempty"`
+	Sources         []*Source `json:"sources"`
+	DifficultyField string    `json:"difficulty_field,omitempty"`
+	ToolField       string    `json:"tool_field,omitempty"`
+	ProjectField    string    `json:"project_field,omitempty"`
+	Assignee        string    `json:"assignee,omitempty"`
+	IncludeSubtasks bool      `json:"include_subtasks,omitempty"`
+	// Credit says how a task with several assignees is shared; the whole task goes
+	// to one assignee when unset.
+	Credit *Credit `json:"credit,omitempty"`
+	// WindowShift moves the weekly Tuesday-to-Tuesday window, e.g. "24h" for Wednesday to Wednesday.
+	WindowShift string `json:"window_shift,omitempty"`
+
+	windowShift time.Duration
+}
+
+// Credit is a team's rule for sharing the points of a task between its assignees.
+type Credit struct {
+	Mode string `json:"mode"`
+	// Weights are the relative shares of the primary assignee, then the others in
+	// tracker order; assignees past the last weight get nothing.
+	Weights []float64 `json:"weights,omitempty"`
+	// Field is the custom field holding the percentages, either in assignee order
+	// ("60/40") or per email ("a@x.com: 60, b@x.com: 40"). Tasks without it are split equally.
+	Field string `json:"field,omitempty"`
+}
+
+// Source is one ClickUp space or tracker project (optionally narrowed to a tag)
+// feeding a team.
+type Source struct {
+	// Tracker names the registered task source: "clickup" (default), "asana", ...
+	Tracker string `json:"tracker,omitempty"`
+	// Space is the ClickUp space of a clickup source.
+	Space string `json:"space,omitempty"`
+	// Project and, optionally, Section select the tasks of any other tracker.
+	Project  string `json:"project,omitempty"`
+	Section  string `json:"section,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	TaskType string `json:"task_type"`
+	// DoneDateFieldID is a date custom field used as the done date instead of
+	// the tracker's own completion date, e.g. the concept team's "done concept" tick date.
+	DoneDateFieldID string `json:"done_date_field_id,omitempty"`
+	DedupeByName    bool   `json:"dedupe_by_name,omitempty"`
+}
+
+// Parse decodes a config, expands ${VAR} references and fills in defaults.
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	if err := json.Unmarshal([]byte(os.ExpandEnv(string(data))), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid sync config: %w", err)
+	}
+	if cfg.Timezone == "" {
+		cfg.Timezone = defaultTimezone
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		fmt.Println("Cannot load sync timezone, fallback ICT:", err)
+		loc = time.FixedZone("ICT", 7*60*60)
+	}
+	cfg.location = loc
+
+	seen := map[string]bool{}
+	for _, t := range cfg.Teams {
+		if t.Team == "" {
+			return nil, fmt.Errorf("invalid sync config: team without a name")
+		}
+		if seen[t.Team] {
+			return nil, fmt.Errorf("invalid sync config: team %s listed twice", t.Team)
+		}
+		seen[t.Team] = true
+		if len(t.Sources) == 0 {
+			return nil, fmt.Errorf("invalid sync config: team %s has no sources", t.Team)
+		}
+		if t.Webhook == "" {
+			t.Webhook = WEBHOOK_TASK
+		}
+		if t.Webhook != WEBHOOK_TASK && t.Webhook != WEBHOOK_CONCEPT {
+			return nil, fmt.Errorf("invalid sync config: team %s has unknown webhook %q", t.Team, t.Webhook)
+		}
+		if t.DifficultyField == "" {
+			t.DifficultyField = t.Team + " Difficult"
+		}
+		if t.ToolField == "" {
+			t.ToolField = "Tool/CTST " + t.Team
+		}
+		if t.ProjectField == "" {
+			t.ProjectField = defaultProjectField
+		}
+		if t.Assignee == "" {
+			t.Assignee = ASSIGNEE_FIRST
+		}
+		if t.Assignee != ASSIGNEE_FIRST && t.Assignee != ASSIGNEE_SECOND {
+			return nil, fmt.Errorf("invalid sync config: team %s has unknown assignee rule %q", t.Team, t.Assignee)
+		}
+		if err := t.parseCredit(); err != nil {
+			return nil, fmt.Errorf("invalid sync config: team %s credit: %w", t.Team, err)
+		}
+		if t.WindowShift != "" {
+			d, err := time.ParseDuration(t.WindowShift)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sync config: team %s window_shift: %w", t.Team, err)
+			}
+			t.windowShift = d
+		}
+		for _, s := range t.Sources {
+			s.Tag = strings.ToLower(strings.TrimSpace(s.Tag))
+			if s.Tracker == "" {
+				s.Tracker = TRACKER_CLICKUP
+			}
+			if !s.Enabled() {
+				// Usually an unset ${CLICKUP_SPACE_ID_*}; the source is skipped rather than failing every team.
+				fmt.Printf("Sync config: team %s has a %s source without a space/project (tag %q), it will be skipped\n", t.Team, s.Tracker, s.Tag)
+			}
+			if s.TaskType == "" {
+				s.TaskType = strings.ToLower(t.Team)
+			}
+		}
+	}
+	return &cfg, nil
+}
+
+// Load reads the file named by SYNC_CONFIG_PATH, or the built-in config when unset.
+func Load() (*Config, error) {
+	path := os.Getenv("SYNC_CONFIG_PATH")
+	if path == "" {
+		return Parse(defaultConfig)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+var (
+	current     *Config
+	currentOnce sync.Once
+)
+
+// Get returns the config loaded on first use. A broken config file falls back to
+// the built-in config rather than stopping every sync.
+func Get() *Config {
+	currentOnce.Do(func() {
+		cfg, err := Load()
+		if err != nil {
+			fmt.Println("Error loading sync config, using built-in config:", err)
+			cfg, err = Parse(defaultConfig)
+		}
+		if err != nil {
+			fmt.Println("Error loading built-in sync config:", err)
+			cfg = &Config{Timezone: defaultTimezone, location: time.FixedZone("ICT", 7*60*60)}
+		}
+		current = cfg
+	})
+	return current
+}
+
+func (c *Config) Location() *time.Location {
+	return c.location
+}
+
+func (c *Config) Team(name string) *Team {
+	for _, t := range c.Teams {
+		if strings.EqualFold(t.Team, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+func (c *Config) TeamNames() []string {
+	names := make([]string, 0, len(c.Teams))
+	for _, t := range c.Teams {
+		names = append(names, t.Team)
+	}
+	return names
+}
+
+// ResolveWebhook finds the team and source a task delivered on the given webhook
+// route belongs to. Teams and sources are tried in config order, so a task in a
+// shared space carrying several tags goes to the first matching team.
+func (c *Config) ResolveWebhook(webhook, spaceID string, tags []string) (*Team, *Source, bool) {
+	tagSet := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tagSet[strings.ToLower(strings.TrimSpace(tag))] = true
+	}
+	for _, t := range c.Teams {
+		if t.Webhook != webhook {
+			continue
+		}
+		for _, s := range t.Sources {
+			if s.Tracker == TRACKER_CLICKUP && s.Space != "" && s.Space == spaceID && (s.Tag == "" || tagSet[s.Tag]) {
+				return t, s, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+func (t *Team) AssigneeIndex(assignees int) int {
+	if t.Assignee == ASSIGNEE_SECOND && assignees > 1 {
+		return 1
+	}
+	return 0
+}
+
+func (t *Team) parseCredit() error {
+	if t.Credit == nil {
+		t.Credit = &Credit{}
+	}
+	if t.Credit.Mode == "" {
+		t.Credit.Mode = CREDIT_SINGLE
+	}
+	switch t.Credit.Mode {
+	case CREDIT_SINGLE, CREDIT_EQUAL:
+	case CREDIT_WEIGHTED:
+		total := 0.0
+		for _, w := range t.Credit.Weights {
+			if w < 0 {
+				return fmt.Errorf("negative weight %v", w)
+			}
+			total += w
+		}
+		if total == 0 {
+			return fmt.Errorf("weighted credit needs weights")
+		}
+	case CREDIT_FIELD:
+		if t.Credit.Field == "" {
+			return fmt.Errorf("field credit needs a field name")
+		}
+	default:
+		return fmt.Errorf("unknown mode %q", t.Credit.Mode)
+	}
+	return nil
+}
+
+// Window is the weekly sync window ending at the most recent Tuesday 00:00,
+// moved by the team's window shift. When that Tuesday is less than five days
+// ago the window is the week before it.
+func (c *Config) Window(t *Team, now time.Time) (from, to time.Time) {
+	nowLocal := now.In(c.location)
+	daysSinceTuesday := (int(nowLocal.Weekday()) - int(time.Tuesday) + 7) % 7
+	to = time.Date(nowLocal.Year(), nowLocal.Month(), nowLocal.Day(), 0, 0, 0, 0, c.location).AddDate(0, 0, -daysSinceTuesday)
+	from = to
+	if nowLocal.Sub(to) < 5*24*time.Hour {
+		from = to.AddDate(0, 0, -7)
+	}
+	return from.Add(t.windowShift), to.Add(t.windowShift)
+}
+
+// WeekStart is the start of the team's weekly window containing at: the Tuesday
+// 00:00 (plus the team's window shift) at or before at.
+func (c *Config) WeekStart(t *Team, at time.Time) time.Time {
+	local := at.In(c.location).Add(-t.windowShift)
+	daysSinceTuesday := (int(local.Weekday()) - int(time.Tuesday) + 7) % 7
+	tuesday := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.location).AddDate(0, 0, -daysSinceTuesday)
+	return tuesday.Add(t.windowShift)
+}
+
+// DoneWeek is the DoneDate a task of team t finished at doneAt is stored under:
+// Monday 09:00 local time of the calendar week in which the team's weekly window
+// containing doneAt ends, the Monday the weekly sync of that window files it
+// under. Every task of one window shares its DoneDate: for Art (Tue → Tue) the
+// tasks done Tue 7 → Mon 13 Jan 2025 and, for PLA (Wed → Wed), those done
+// Wed 8 → Tue 14 Jan are all stored under Mon 13 Jan 09:00.
+func (c *Config) DoneWeek(t *Team, doneAt time.Time) time.Time {
+	end := c.WeekStart(t, doneAt).AddDate(0, 0, 7).In(c.location)
+	daysSinceMonday := (int(end.Weekday()) + 6) % 7
+	monday := end.AddDate(0, 0, -daysSinceMonday)
+	return time.Date(monday.Year(), monday.Month(), monday.Day(), 9, 0, 0, 0, c.location)
+}
+
+// Enabled reports whether the source can be fetched, i.e. its space or project is set.
+func (s *Source) Enabled() bool {
+	if s.Tracker == TRACKER_CLICKUP {
+		return s.Space != ""
+	}
+	return s.Project != ""
+}
@@ -0,0 +1,86 @@
+package syncconfig
+
+import (
+	"testing"
+	"time"
+)
+
+func testConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg, err := Parse([]byte(`{
+		"timezone": "Asia/Ho_Chi_Minh",
+		"teams": [
+			{"team": "Art", "sources": [{"space": "1"}]},
+			{"team": "PLA", "window_shift": "24h", "sources": [{"space": "2"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestDoneWeek(t *testing.T) {
+	cfg := testConfig(t)
+	loc := cfg.Location()
+	at := func(day, hour, min int) time.Time { return time.Date(2025, time.January, day, hour, min, 0, 0, loc) }
+	monday := func(day int) time.Time { return time.Date(2025, time.January, day, 9, 0, 0, 0, loc) }
+
+	tests := []struct {
+		team   string
+		doneAt time.Time
+		want   time.Time
+	}{
+		// Art's window runs Tue 00:00 → Tue 00:00: Tue 7 → Mon 13 share Mon 13.
+		{"Art", at(6, 23, 59), monday(6)},
+		{"Art", at(7, 0, 0), monday(13)},
+		{"Art", at(7, 12, 0), monday(13)},
+		{"Art", at(8, 12, 0), monday(13)},
+		{"Art", at(9, 12, 0), monday(13)},
+		{"Art", at(10, 12, 0), monday(13)},
+		{"Art", at(11, 12, 0), monday(13)},
+		{"Art", at(12, 12, 0), monday(13)},
+		{"Art", at(13, 23, 59), monday(13)},
+		{"Art", at(14, 0, 0), monday(20)},
+		{"Art", at(14, 12, 0), monday(20)},
+		// PLA's window is shifted by a day, Wed 00:00 → Wed 00:00: Wed 8 → Tue 14 share Mon 13.
+		{"PLA", at(7, 12, 0), monday(6)},
+		{"PLA", at(7, 23, 59), monday(6)},
+		{"PLA", at(8, 0, 0), monday(13)},
+		{"PLA", at(9, 12, 0), monday(13)},
+		{"PLA", at(10, 12, 0), monday(13)},
+		{"PLA", at(11, 12, 0), monday(13)},
+		{"PLA", at(12, 12, 0), monday(13)},
+		{"PLA", at(13, 12, 0), monday(13)},
+		{"PLA", at(14, 23, 59), monday(13)},
+		{"PLA", at(15, 0, 0), monday(20)},
+	}
+	for _, tt := range tests {
+		got := cfg.DoneWeek(cfg.Team(tt.team), tt.doneAt)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s done %s: stored under %s, want %s", tt.team, tt.doneAt.Format("Mon 2006-01-02 15:04"), got.In(loc).Format("Mon 2006-01-02 15:04"), tt.want.Format("Mon 2006-01-02 15:04"))
+		}
+	}
+}
+
+// The weekly sync runs Wednesday 00:00; every task of the window it fetches is
+// stored under the Monday 09:00 of that week, as before the sync config.
+func TestDoneWeekMatchesSyncWindow(t *testing.T) {
+	cfg := testConfig(t)
+	loc := cfg.Location()
+	syncAt := time.Date(2025, time.January, 15, 0, 0, 0, 0, loc)
+	want := time.Date(2025, time.January, 13, 9, 0, 0, 0, loc)
+
+	for _, name := range cfg.TeamNames() {
+		team := cfg.Team(name)
+		from, to := cfg.Window(team, syncAt)
+		for doneAt := from; doneAt.Before(to); doneAt = doneAt.Add(time.Hour) {
+			if got := cfg.DoneWeek(team, doneAt); !got.Equal(want) {
+				t.Fatalf("%s window %s → %s: task done %s stored under %s, want %s", name, from, to, doneAt, got, want)
+			}
+		}
+		if got := cfg.DoneWeek(team, to.Add(-time.Nanosecond)); !got.Equal(want) {
+			t.Errorf("%s: last instant of the window stored under %s, want %s", name, got, want)
+		}
+	}
+}
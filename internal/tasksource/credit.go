@@ -0,0 +1,124 @@
+package tasksource
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+)
+
+// SplitCredit shares a task between its assignees (emails in tracker order) by
+// the team's credit rule; primary is the index picked by the team's assignee rule.
+// field is the value of the team's credit field, "" when the task has none. The
+// shares are relative; CompletedTask.NormalizeContributors scales them. Nil means
+// the primary assignee earns the whole task.
+func SplitCredit(team *syncconfig.Team, assignees []string, primary int, field string) ([]collectionmodels.Contributor, error) {
+	credit := team.Credit
+	if credit == nil || credit.Mode == syncconfig.CREDIT_SINGLE || len(assignees) < 2 {
+		return nil, nil
+	}
+
+	contributors := make([]collectionmodels.Contributor, len(assignees))
+	for i, email := range assignees {
+		contributors[i] = collectionmodels.Contributor{AssigneeID: email, Share: 1}
+	}
+
+	switch credit.Mode {
+	case syncconfig.CREDIT_WEIGHTED:
+		// The primary assignee takes the first weight, the others follow in tracker order.
+		position := 1
+		for i := range contributors {
+			weight := 0
+			if i != primary {
+				weight = position
+				position++
+			}
+			contributors[i].Share = 0
+			if weight < len(credit.Weights) {
+				contributors[i].Share = credit.Weights[weight]
+			}
+		}
+	case syncconfig.CREDIT_FIELD:
+		if strings.TrimSpace(field) == "" {
+			break
+		}
+		shares, err := parseCreditField(field, assignees)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q value %q: %w", credit.Field, field, err)
+		}
+		for i := range contributors {
+			contributors[i].Share = shares[i]
+		}
+	}
+	return contributors, nil
+}
+
+// parseCreditField reads percentages in assignee order ("60/40", "60%, 40%") or
+// per email ("a@x.com: 60, b@x.com = 40"; unlisted assignees get nothing).
+func parseCreditField(value string, assignees []string) ([]float64, error) {
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || r == '/' || r == '\n'
+	})
+	shares := make([]float64, len(assignees))
+	byEmail := strings.ContainsAny(value, ":=")
+	if !byEmail && len(parts) != len(assignees) {
+		return nil, fmt.Errorf("%d shares for %d assignees", len(parts), len(assignees))
+	}
+	for i, part := range parts {
+		share := part
+		if byEmail {
+			email, rest, ok := strings.Cut(part, ":")
+			if !ok {
+				email, rest, ok = strings.Cut(part, "=")
+			}
+			if !ok {
+				return nil, fmt.Errorf("%q has no email", strings.TrimSpace(part))
+			}
+			i = indexFold(assignees, strings.TrimSpace(email))
+			if i == -1 {
+				return nil, fmt.Errorf("%s is not an assignee", strings.TrimSpace(email))
+			}
+			share = rest
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(share), "%")), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", strings.TrimSpace(share))
+		}
+		shares[i] = n
+	}
+	return shares, nil
+}
+
+func indexFold(list []string, s string) int {
+	for i, item := range list {
+		if strings.EqualFold(item, s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// MergeContributors renames contributors, given in assignee order, to members[i]
+// and adds up the shares of assignees that turn out to be the same member.
+func MergeContributors(contributors []collectionmodels.Contributor, members []string) []collectionmodels.Contributor {
+	if len(contributors) == 0 {
+		return contributors
+	}
+	merged := make([]collectionmodels.Contributor, 0, len(contributors))
+	at := map[string]int{}
+	for i, c := range contributors {
+		id := c.AssigneeID
+		if i < len(members) {
+			id = members[i]
+		}
+		if j, ok := at[id]; ok {
+			merged[j].Share += c.Share
+			continue
+		}
+		at[id] = len(merged)
+		merged = append(merged, collectionmodels.Contributor{AssigneeID: id, Share: c.Share})
+	}
+	return merged
+}
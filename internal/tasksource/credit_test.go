@@ -0,0 +1,106 @@
+package tasksource
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+)
+
+type share = collectionmodels.Contributor
+
+func contributor(id string, s float64) share {
+	return share{AssigneeID: id, Share: s}
+}
+
+// credit runs a task's assignees through SplitCredit and NormalizeContributors,
+// as a sync does, and returns the stored AssigneeID and contributors.
+func credit(t *testing.T, c *syncconfig.Credit, assignees []string, primary int, field string) (string, []share, error) {
+	t.Helper()
+	contributors, err := SplitCredit(&syncconfig.Team{Team: "Art", Credit: c}, assignees, primary, field)
+	if err != nil {
+		return "", nil, err
+	}
+	task := &collectionmodels.CompletedTask{AssigneeID: assignees[primary], Contributors: contributors}
+	if err := task.NormalizeContributors(); err != nil {
+		return "", nil, err
+	}
+	return task.AssigneeID, task.Contributors, nil
+}
+
+func TestSplitCredit(t *testing.T) {
+	weighted := &syncconfig.Credit{Mode: syncconfig.CREDIT_WEIGHTED, Weights: []float64{50, 30, 20}}
+	twoWeights := &syncconfig.Credit{Mode: syncconfig.CREDIT_WEIGHTED, Weights: []float64{70, 30}}
+	field := &syncconfig.Credit{Mode: syncconfig.CREDIT_FIELD, Field: "Credit"}
+	abc := []string{"a@x.com", "b@x.com", "c@x.com"}
+	ab := abc[:2]
+
+	tests := []struct {
+		name         string
+		credit       *syncconfig.Credit
+		assignees    []string
+		primary      int
+		field        string
+		wantAssignee string
+		want         []share
+		wantErr      string
+	}{
+		{name: "single", credit: &syncconfig.Credit{Mode: syncconfig.CREDIT_SINGLE}, assignees: ab, primary: 1, wantAssignee: "b@x.com"},
+		{name: "equal", credit: &syncconfig.Credit{Mode: syncconfig.CREDIT_EQUAL}, assignees: ab, wantAssignee: "a@x.com", want: []share{contributor("a@x.com", 0.5), contributor("b@x.com", 0.5)}},
+		{name: "one assignee", credit: weighted, assignees: abc[:1], wantAssignee: "a@x.com"},
+		{name: "weighted, primary first", credit: weighted, assignees: abc, primary: 0, wantAssignee: "a@x.com", want: []share{contributor("a@x.com", 0.5), contributor("b@x.com", 0.3), contributor("c@x.com", 0.2)}},
+		{name: "weighted, primary second", credit: weighted, assignees: abc, primary: 1, wantAssignee: "b@x.com", want: []share{contributor("a@x.com", 0.3), contributor("b@x.com", 0.5), contributor("c@x.com", 0.2)}},
+		{name: "weighted, more assignees than weights", credit: twoWeights, assignees: abc, primary: 0, wantAssignee: "a@x.com", want: []share{contributor("a@x.com", 0.7), contributor("b@x.com", 0.3)}},
+		{name: "weighted, last primary past the weights", credit: twoWeights, assignees: abc, primary: 2, wantAssignee: "c@x.com", want: []share{contributor("a@x.com", 0.3), contributor("c@x.com", 0.7)}},
+		{name: "field in order", credit: field, assignees: ab, field: "60/40", wantAssignee: "a@x.com", want: []share{contributor("a@x.com", 0.6), contributor("b@x.com", 0.4)}},
+		{name: "field in order with percent signs", credit: field, assignees: ab, field: "60%, 40%", wantAssignee: "a@x.com", want: []share{contributor("a@x.com", 0.6), contributor("b@x.com", 0.4)}},
+		{name: "field per email", credit: field, assignees: ab, field: "B@x.com: 25, a@x.com = 75", wantAssignee: "a@x.com", want: []share{contributor("a@x.com", 0.75), contributor("b@x.com", 0.25)}},
+		{name: "field naming one assignee", credit: field, assignees: ab, primary: 1, field: "a@x.com: 100", wantAssignee: "a@x.com"},
+		{name: "field missing", credit: field, assignees: ab, field: " ", wantAssignee: "a@x.com", want: []share{contributor("a@x.com", 0.5), contributor("b@x.com", 0.5)}},
+		{name: "field with the wrong count", credit: field, assignees: ab, field: "60/30/10", wantErr: "3 shares for 2 assignees"},
+		{name: "field with an unknown email", credit: field, assignees: ab, field: "a@x.com: 50, c@x.com: 50", wantErr: "c@x.com is not an assignee"},
+		{name: "field that is not a number", credit: field, assignees: ab, field: "sixty/forty", wantErr: "is not a number"},
+		{name: "field with a negative share", credit: field, assignees: ab, field: "-10/110", wantErr: "invalid share"},
+		{name: "field with only zero shares", credit: field, assignees: ab, field: "0/0", wantErr: "no share"},
+	}
+	for _, tt := range tests {
+		assignee, contributors, err := credit(t, tt.credit, tt.assignees, tt.primary, tt.field)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want one containing %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if assignee != tt.wantAssignee || !reflect.DeepEqual(contributors, tt.want) {
+			t.Errorf("%s: assignee %s contributors %v, want %s %v", tt.name, assignee, contributors, tt.wantAssignee, tt.want)
+		}
+	}
+}
+
+func TestMergeContributors(t *testing.T) {
+	// Two ClickUp accounts of the same member, e.g. a work and a personal one.
+	split := []share{contributor("1001", 1), contributor("1002", 1), contributor("1003", 1)}
+	merged := MergeContributors(split, []string{"m@x.com", "n@x.com", "m@x.com"})
+	if want := []share{contributor("m@x.com", 2), contributor("n@x.com", 1)}; !reflect.DeepEqual(merged, want) {
+		t.Errorf("merged %v, want %v", merged, want)
+	}
+
+	// Both assignees are the member: the task is theirs alone.
+	task := &collectionmodels.CompletedTask{AssigneeID: "m@x.com", Contributors: MergeContributors([]share{contributor("1001", 0.5), contributor("1003", 0.5)}, []string{"m@x.com", "m@x.com"})}
+	if err := task.NormalizeContributors(); err != nil {
+		t.Fatal(err)
+	}
+	if task.AssigneeID != "m@x.com" || task.Contributors != nil {
+		t.Errorf("task credited to %s with contributors %v, want m@x.com alone", task.AssigneeID, task.Contributors)
+	}
+
+	if got := MergeContributors(nil, []string{"m@x.com"}); got != nil {
+		t.Errorf("merging no contributors gave %v", got)
+	}
+}
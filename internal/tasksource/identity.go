@@ -0,0 +1,31 @@
+package tasksource
+
+import "sync"
+
+// AssigneeResolver maps an assignee as a tracker reports it (its account id and
+// email) to the member identifier completed tasks are stored under.
+type AssigneeResolver func(tracker, accountID, email string) string
+
+var (
+	resolverMu sync.RWMutex
+	resolver   AssigneeResolver
+)
+
+// SetAssigneeResolver installs the resolver used by ResolveAssignee.
+func SetAssigneeResolver(r AssigneeResolver) {
+	resolverMu.Lock()
+	defer resolverMu.Unlock()
+	resolver = r
+}
+
+// ResolveAssignee returns the member identifier of a tracker assignee, or email
+// unchanged when no resolver is installed or the assignee matches no member.
+func ResolveAssignee(tracker, accountID, email string) string {
+	resolverMu.RLock()
+	r := resolver
+	resolverMu.RUnlock()
+	if r == nil {
+		return email
+	}
+	return r(tracker, accountID, email)
+}
@@ -0,0 +1,63 @@
+package tasksource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+)
+
+// Manual is the source of records entered through the API. Nothing is pulled
+// from it; entries are pushed and only normalized here.
+type Manual struct{}
+
+func init() {
+	Register(Manual{})
+}
+
+func (Manual) Name() string {
+	return collectionmodels.SOURCE_MANUAL
+}
+
+func (Manual) FetchWindow(ctx context.Context, team *syncconfig.Team, source *syncconfig.Source, from, to time.Time) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (Manual) FetchSingle(ctx context.Context, team *syncconfig.Team, source *syncconfig.Source, taskID string) (*collectionmodels.CompletedTask, error) {
+	return nil, ErrNotSupported
+}
+
+// Normalize decodes a completed task as sent to /update-task-done. The team, when
+// given, overrides the team of the entry.
+func (Manual) Normalize(team *syncconfig.Team, source *syncconfig.Source, raw json.RawMessage) (*collectionmodels.CompletedTask, error) {
+	var task collectionmodels.CompletedTask
+	if err := json.Unmarshal(raw, &task); err != nil {
+		return nil, fmt.Errorf("invalid task: %w", err)
+	}
+	if task.TaskID == "" {
+		return nil, fmt.Errorf("missing task id")
+	}
+	if team != nil {
+		task.Team = team.Team
+	}
+	if source != nil && source.TaskType != "" {
+		task.TaskType = source.TaskType
+	}
+	// Entries may name a member by any of their email aliases.
+	task.AssigneeID = ResolveAssignee(collectionmodels.SOURCE_MANUAL, "", task.AssigneeID)
+	members := make([]string, len(task.Contributors))
+	for i, c := range task.Contributors {
+		members[i] = ResolveAssignee(collectionmodels.SOURCE_MANUAL, "", c.AssigneeID)
+	}
+	task.Contributors = MergeContributors(task.Contributors, members)
+	if err := task.NormalizeContributors(); err != nil {
+		return nil, fmt.Errorf("invalid contributors: %w", err)
+	}
+	task.Source = collectionmodels.SOURCE_MANUAL
+	// Voiding is the sync's business, not something an entry can set.
+	task.Void, task.VoidReason, task.VoidedAt = false, "", nil
+	return &task, nil
+}
@@ -0,0 +1,93 @@
+// Package tasksource defines the trackers completed tasks come from. Each tracker
+// package registers a TaskSource under the tracker name used in the sync config
+// ("clickup", "asana", ...), and the name is stored as the source of every
+// completed-task record it produces.
+package tasksource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	collectionmodels "performance-dashboard-backend/internal/database/collection_models"
+	"performance-dashboard-backend/internal/syncconfig"
+)
+
+// ErrNotSupported is returned by a source for an operation its tracker has no equivalent of.
+var ErrNotSupported = errors.New("not supported by this task source")
+
+// TaskSource reads completed tasks from one tracker and normalizes them into
+// completed-task records using the team's field rules.
+type TaskSource interface {
+	Name() string
+	// FetchWindow returns the tasks of one configured source completed in [from, to).
+	FetchWindow(ctx context.Context, team *syncconfig.Team, source *syncconfig.Source, from, to time.Time) (*Result, error)
+	// FetchSingle returns the record a task should have now, or nil when it is not done.
+	FetchSingle(ctx context.Context, team *syncconfig.Team, source *syncconfig.Source, taskID string) (*collectionmodels.CompletedTask, error)
+	// Normalize converts one task as the tracker represents it in JSON.
+	Normalize(team *syncconfig.Team, source *syncconfig.Source, raw json.RawMessage) (*collectionmodels.CompletedTask, error)
+}
+
+// Result is what fetching a window produced: the accepted tasks plus what was
+// filtered out along the way.
+type Result struct {
+	Tasks      []*collectionmodels.CompletedTask
+	Fetched    int
+	Skipped    int
+	Rejections []collectionmodels.TaskRejection
+}
+
+// Add merges another result into r.
+func (r *Result) Add(other *Result) {
+	r.Tasks = append(r.Tasks, other.Tasks...)
+	r.Fetched += other.Fetched
+	r.Skipped += other.Skipped
+	r.Rejections = append(r.Rejections, other.Rejections...)
+}
+
+// RejectionError is returned when a task's tracker fields cannot be scored. The
+// rejection carries what the team lead needs to fix the task.
+type RejectionError struct {
+	Rejection collectionmodels.TaskRejection
+}
+
+func (e *RejectionError) Error() string {
+	return fmt.Sprintf("%s for task %s", e.Rejection.Reason, e.Rejection.TaskID)
+}
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = map[string]TaskSource{}
+)
+
+// Register makes a source available under its name; registering a name twice panics.
+func Register(source TaskSource) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if _, ok := sources[source.Name()]; ok {
+		panic("tasksource: " + source.Name() + " registered twice")
+	}
+	sources[source.Name()] = source
+}
+
+func Get(name string) (TaskSource, bool) {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	source, ok := sources[name]
+	return source, ok
+}
+
+func Names() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}